@@ -0,0 +1,63 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-4/internal/bingo"
+)
+
+// An application contains application wide data such as Logger.
+type application struct {
+	log *log.Logger
+}
+
+// writeOutput writes generated input to the file at the given path or to stdout if path is "-".
+func (app *application) writeOutput(filePath string, input *bingo.GeneratedInput) error {
+	if filePath == "-" {
+		return input.Write(os.Stdout)
+	}
+
+	file, err := os.Create(filePath)
+
+	if err != nil {
+		return err
+	}
+
+	// Defer close the file.
+	defer func() {
+		err = file.Close()
+
+		if err != nil {
+			app.log.Printf("Failed to close file: %s\n", filePath)
+		}
+	}()
+
+	return input.Write(file)
+}
+
+func main() {
+	var outFile = flag.String("out", "-", "Output file (\"-\" writes to stdout).")
+	var cards = flag.Int("cards", 100, "Number of unique cards.")
+	var pool = flag.Int("pool", 100, "Size of the number pool, values are 0 ... pool-1.")
+	var size = flag.Int("size", bingo.BoardSize, "Number of rows and columns of a card.")
+	var seed = flag.Int64("seed", 1, "Random generator seed.")
+	var noWinBefore = flag.Int("no-win-before", 0, "No card wins before this draw (0 disables constraint).")
+	var singleWinnerAt = flag.Int("single-winner-at", 0,
+		"Exactly one card wins on this draw and none before it (0 disables constraint).")
+	flag.Parse()
+
+	app := application{log: log.Default()}
+
+	input, err := bingo.Generate(bingo.GeneratorConfig{Cards: *cards, PoolSize: *pool, Size: *size, Seed: *seed,
+		NoWinBefore: *noWinBefore, SingleWinnerAt: *singleWinnerAt})
+
+	if err != nil {
+		app.log.Fatalf("Encountered error while generating bingo input (%s).", err.Error())
+	}
+
+	if err := app.writeOutput(*outFile, input); err != nil {
+		app.log.Fatalf("Encountered error while writing bingo input (%s).", err.Error())
+	}
+}
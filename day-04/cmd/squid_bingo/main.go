@@ -1,14 +1,12 @@
 package main
 
 import (
-	"bufio"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
-	"strconv"
-	"strings"
 
 	"github.com/PrimozLavric/advent-of-code-2021/day-4/internal/bingo"
 )
@@ -18,11 +16,21 @@ type application struct {
 	log *log.Logger
 }
 
-func (app *application) parseBingoFile(filePath string) ([]int, []*bingo.Board, error) {
-	file, err := os.Open(filePath)
+// openInput opens the file at the given path or returns stdin if path is "-".
+func openInput(filePath string) (io.ReadCloser, error) {
+	if filePath == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	return os.Open(filePath)
+}
+
+// readInput opens the input and passes it to the provided read function.
+func (app *application) readInput(filePath string, read func(r io.Reader) error) error {
+	file, err := openInput(filePath)
 
 	if err != nil {
-		return nil, nil, err
+		return err
 	}
 
 	// Defer close the file.
@@ -34,135 +42,160 @@ func (app *application) parseBingoFile(filePath string) ([]int, []*bingo.Board,
 		}
 	}()
 
-	fileScanner := bufio.NewScanner(file)
-	fileScanner.Split(bufio.ScanLines)
+	return read(file)
+}
+
+// parseBingoFile reads the bingo sequence and boards. If sequenceFile is set, the sequence is read from it instead and
+// the bingo file is expected to hold only boards.
+func (app *application) parseBingoFile(filePath string, sequenceFile string) ([]int, []*bingo.Board, error) {
+	var sequence []int
+	var boards []*bingo.Board
 
-	// Read bingo sequence.
-	var bingoSequence []int
+	if sequenceFile == "" {
+		err := app.readInput(filePath, func(r io.Reader) error {
+			var err error
+			sequence, boards, err = bingo.Parse(r)
+			return err
+		})
 
-	if !fileScanner.Scan() {
-		return nil, nil, errors.New("bad file format, file contains no data")
+		return sequence, boards, err
 	}
 
-	for i, strEntry := range strings.Split(fileScanner.Text(), ",") {
-		entry, err := strconv.Atoi(strEntry)
+	if filePath == "-" && sequenceFile == "-" {
+		return nil, nil, errors.New("bingo file and sequence file cannot both be read from stdin")
+	}
 
-		if err != nil {
-			return nil, nil, errors.New(fmt.Sprintf("could not parse %d-th element of bingo sequence (%s)", i, err.Error()))
-		}
+	err := app.readInput(sequenceFile, func(r io.Reader) error {
+		var err error
+		sequence, err = bingo.ParseSequence(r)
+		return err
+	})
 
-		bingoSequence = append(bingoSequence, entry)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	var bingoBoards []*bingo.Board
-	var boardValues [bingo.BoardSize][bingo.BoardSize]int
+	err = app.readInput(filePath, func(r io.Reader) error {
+		var err error
+		boards, err = bingo.ParseBoards(r)
+		return err
+	})
 
-	rowIdx := 0
-	for fileScanner.Scan() {
-		rowValues := strings.Fields(fileScanner.Text())
-
-		if len(rowValues) == 0 {
-			if rowIdx == bingo.BoardSize {
-				bingoBoards = append(bingoBoards, bingo.NewBoard(boardValues))
-				rowIdx = 0
-			}
+	return sequence, boards, err
+}
 
-			if rowIdx != bingo.BoardSize && rowIdx != 0 {
-				return nil, nil, errors.New("bad file format, bad bingo board format")
-			}
+// printRanking prints the first and the last board to win according to the provided game ranking.
+func (app *application) printRanking(ranking []bingo.WinRecord, boardCount int) {
+	if len(ranking) == 0 {
+		fmt.Println("No board won.")
+		return
+	}
 
-			rowIdx = 0
-			continue
-		}
+	first := ranking[0]
+	fmt.Printf("Board %d won with score %d\n", first.BoardIdx+1, first.Score)
 
-		if len(rowValues) != bingo.BoardSize {
-			return nil, nil, errors.New(fmt.Sprintf("bad file format, invalid column count %d, expected %d", len(rowValues), bingo.BoardSize))
-		}
+	if len(ranking) != boardCount {
+		fmt.Println("Not every board won.")
+		return
+	}
 
-		// Populate row
-		for i := 0; i < bingo.BoardSize; i++ {
-			value, err := strconv.Atoi(rowValues[i])
+	last := ranking[len(ranking)-1]
+	fmt.Printf("Board %d wins last with score %d\n", last.BoardIdx+1, last.Score)
+}
 
-			if err != nil {
-				return nil, nil, errors.New(fmt.Sprintf("bad file format, bad bingo board format (%s)", err.Error()))
-			}
+// printWinProbabilities prints each board's probability to win first and its expected number of draws until the win.
+func (app *application) printWinProbabilities(probabilities *bingo.WinProbabilities) {
+	fmt.Printf("Win probabilities over %d draw orders:\n", probabilities.Trials)
 
-			boardValues[rowIdx][i] = value
+	for i, card := range probabilities.Cards {
+		expectedDraws := 0.0
+		for draws, p := range card.DrawsToWin {
+			expectedDraws += float64(draws) * p
 		}
 
-		rowIdx++
+		fmt.Printf("Board %d wins first with probability %.4f [%.4f, %.4f], never wins with probability %.4f, "+
+			"expected winning draw %.2f\n", i+1, card.WinFirst.P, card.WinFirst.Low, card.WinFirst.High,
+			card.DrawsToWin[0], expectedDraws)
 	}
-
-	return bingoSequence, bingoBoards, nil
 }
 
-func (app *application) findAndPrintWinningBoard(bingoSequence []int, bingoBoards []*bingo.Board) {
-	for _, value := range bingoSequence {
-		for i, board := range bingoBoards {
-			won, err := board.MarkValue(value)
+// printWinningSequence prints the shortest draw sequence that makes the target board win first.
+func (app *application) printWinningSequence(boards []*bingo.Board, target int, pool []int) {
+	sequence, err := bingo.FindWinningSequence(boards, target, pool)
 
-			if err != nil {
-				app.log.Panicf("Encountered error while playing bingo (%s).", err.Error())
-				return
-			}
+	if errors.Is(err, bingo.ErrNoWinningSequence) {
+		fmt.Printf("Board %d cannot win first.\n", target+1)
+		return
+	}
 
-			if won {
-				fmt.Printf("Board %d won with score %d\n", i+1, board.Score())
-				return
-			}
-		}
+	if err != nil {
+		app.log.Fatalf("Encountered error while searching for winning sequence (%s).", err.Error())
 	}
 
-	fmt.Println("No board won.")
+	fmt.Printf("Board %d wins first after drawing %v\n", target+1, sequence)
 }
 
-func (app *application) findAndPrintBoardThatWinsLast(bingoSequence []int, bingoBoards []*bingo.Board) {
-	boardsWonCount := 0
-
-	for _, value := range bingoSequence {
-		for i, board := range bingoBoards {
-			if board.Won() {
-				continue
-			}
-
-			won, err := board.MarkValue(value)
-
-			if err != nil {
-				app.log.Panicf("Encountered error while playing bingo (%s).", err.Error())
-				return
-			}
-
-			if won {
-				boardsWonCount++
-				if boardsWonCount == len(bingoBoards) {
-					fmt.Printf("Board %d wins last with score %d", i+1, board.Score())
-					return
-				}
-			}
+// distinctValues returns values of the sequence with duplicates removed, preserving order of first occurrence.
+func distinctValues(sequence []int) []int {
+	seen := make(map[int]bool)
+	var values []int
+
+	for _, value := range sequence {
+		if !seen[value] {
+			seen[value] = true
+			values = append(values, value)
 		}
 	}
 
-	fmt.Println("Not every board won.")
+	return values
 }
 
 func main() {
-	var bingoFile = flag.String("file", "input.txt", "File containing bingo data.")
+	var bingoFile = flag.String("file", "input.txt", "File containing bingo data (\"-\" reads stdin).")
+	var sequenceFile = flag.String("sequence", "", "File containing the draw sequence (\"-\" reads stdin). When set, bingo file must contain only boards.")
+	var trials = flag.Int("trials", 0, "Number of random draw orders used to estimate win probabilities (0 disables estimation).")
+	var seed = flag.Int64("seed", 1, "Seed of the win probability estimation.")
+	var exact = flag.Bool("exact", false, "Compute exact win probabilities by enumerating all draw orders (small pools only).")
+	var target = flag.Int("target", 0, "Find the shortest draw sequence that makes this board (1-based) win first (0 disables search).")
 	flag.Parse()
 
 	app := application{log: log.Default()}
 
-	bingoSequence, bingoBoards, err := app.parseBingoFile(*bingoFile)
+	bingoSequence, bingoBoards, err := app.parseBingoFile(*bingoFile, *sequenceFile)
 
 	if err != nil {
 		app.log.Fatalf("Encountered error during bingo file parsing (%s).", err.Error())
 	}
 
-	app.findAndPrintWinningBoard(bingoSequence, bingoBoards)
+	game := bingo.NewGame(bingoSequence, bingoBoards)
+	ranking, err := game.Play()
+
+	if err != nil {
+		app.log.Fatalf("Encountered error while playing bingo (%s).", err.Error())
+	}
+
+	app.printRanking(ranking, game.BoardCount())
+
+	if *target > 0 {
+		app.printWinningSequence(bingoBoards, *target-1, distinctValues(bingoSequence))
+	}
+
+	if *trials <= 0 && !*exact {
+		return
+	}
 
-	// Reset boards.
-	for _, board := range bingoBoards {
-		board.Reset()
+	pool := distinctValues(bingoSequence)
+	var probabilities *bingo.WinProbabilities
+
+	if *exact {
+		probabilities, err = bingo.ExactWinProbabilities(bingoBoards, pool)
+	} else {
+		probabilities, err = bingo.EstimateWinProbabilities(bingoBoards, bingo.MonteCarloConfig{Pool: pool, Trials: *trials, Seed: *seed})
+	}
+
+	if err != nil {
+		app.log.Fatalf("Encountered error while computing win probabilities (%s).", err.Error())
 	}
 
-	app.findAndPrintBoardThatWinsLast(bingoSequence, bingoBoards)
+	app.printWinProbabilities(probabilities)
 }
@@ -1,7 +1,11 @@
 package bingo
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
+// BoardSize is the size of standard bingo boards. Boards of other sizes are created with NewSquareBoard.
 const BoardSize = 5
 
 type field struct {
@@ -10,16 +14,47 @@ type field struct {
 }
 
 type Board struct {
-	grid  [BoardSize][BoardSize]field
+	grid  [][]field
 	won   bool
 	score int
+	// winningValue value whose marking completed a line, zero if the board has not won.
+	winningValue int
 }
 
 func NewBoard(values [BoardSize][BoardSize]int) *Board {
-	b := Board{}
+	rows := make([][]int, BoardSize)
 
-	for i := 0; i < BoardSize; i++ {
-		for j := 0; j < BoardSize; j++ {
+	for i := range rows {
+		rows[i] = values[i][:]
+	}
+
+	return newBoard(rows)
+}
+
+// NewSquareBoard creates a board of any size from its rows. Board must have as many rows as columns.
+func NewSquareBoard(values [][]int) (*Board, error) {
+	if len(values) == 0 {
+		return nil, errors.New("board has no rows")
+	}
+
+	for i, row := range values {
+		if len(row) != len(values) {
+			return nil, errors.New(fmt.Sprintf("board is not square, row %d has %d values, expected %d", i+1, len(row),
+				len(values)))
+		}
+	}
+
+	return newBoard(values), nil
+}
+
+// newBoard creates board from rows of a square grid.
+func newBoard(values [][]int) *Board {
+	b := Board{grid: make([][]field, len(values))}
+
+	for i := range b.grid {
+		b.grid[i] = make([]field, len(values))
+
+		for j := range b.grid[i] {
 			b.grid[i][j].value = values[i][j]
 		}
 	}
@@ -27,13 +62,30 @@ func NewBoard(values [BoardSize][BoardSize]int) *Board {
 	return &b
 }
 
+// Size returns number of rows and columns of the board.
+func (b *Board) Size() int {
+	return len(b.grid)
+}
+
+// Clone returns a deep copy of the board.
+func (b *Board) Clone() *Board {
+	c := *b
+	c.grid = make([][]field, len(b.grid))
+
+	for i := range c.grid {
+		c.grid[i] = append([]field(nil), b.grid[i]...)
+	}
+
+	return &c
+}
+
 func (b *Board) MarkValue(value int) (bool, error) {
 	if b.won {
 		return true, errors.New("cannot mark value, because board already won")
 	}
 
-	for i := 0; i < BoardSize; i++ {
-		for j := 0; j < BoardSize; j++ {
+	for i := range b.grid {
+		for j := range b.grid[i] {
 			field := &b.grid[i][j]
 			if field.value == value {
 				if field.marked {
@@ -45,6 +97,7 @@ func (b *Board) MarkValue(value int) (bool, error) {
 
 				if b.checkWinningCondition(i, j) {
 					b.won = true
+					b.winningValue = value
 					b.score = b.computeScore(value)
 					return true, nil
 				}
@@ -56,13 +109,14 @@ func (b *Board) MarkValue(value int) (bool, error) {
 }
 
 func (b *Board) Reset() {
-	for i := 0; i < BoardSize; i++ {
-		for j := 0; j < BoardSize; j++ {
+	for i := range b.grid {
+		for j := range b.grid[i] {
 			b.grid[i][j].marked = false
 		}
 	}
 	b.won = false
 	b.score = 0
+	b.winningValue = 0
 }
 
 func (b *Board) Won() bool {
@@ -80,8 +134,8 @@ func (b *Board) Score() int {
 func (b *Board) computeScore(lastValue int) int {
 	score := 0
 
-	for i := 0; i < BoardSize; i++ {
-		for j := 0; j < BoardSize; j++ {
+	for i := range b.grid {
+		for j := range b.grid[i] {
 			field := &b.grid[i][j]
 			if !field.marked {
 				score += field.value
@@ -96,7 +150,7 @@ func (b *Board) checkWinningCondition(row int, column int) bool {
 	// Check rows.
 	allMarked := true
 
-	for i := 0; i < BoardSize; i++ {
+	for i := range b.grid {
 		if !b.grid[row][i].marked {
 			allMarked = false
 			break
@@ -111,7 +165,7 @@ func (b *Board) checkWinningCondition(row int, column int) bool {
 	// Check columns.
 	allMarked = true
 
-	for i := 0; i < BoardSize; i++ {
+	for i := range b.grid {
 		if !b.grid[i][column].marked {
 			allMarked = false
 			break
@@ -0,0 +1,128 @@
+package bingo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoWinningSequence is returned by FindWinningSequence when the target board cannot win before all other boards.
+var ErrNoWinningSequence = errors.New("no draw sequence makes the target board win first")
+
+// lines returns unmarked values of every row and column of the board.
+func (b *Board) lines() [][]int {
+	var lines [][]int
+
+	for i := range b.grid {
+		var row, column []int
+
+		for j := range b.grid[i] {
+			if !b.grid[i][j].marked {
+				row = append(row, b.grid[i][j].value)
+			}
+
+			if !b.grid[j][i].marked {
+				column = append(column, b.grid[j][i].value)
+			}
+		}
+
+		lines = append(lines, row, column)
+	}
+
+	return lines
+}
+
+// completedBy reports whether drawing the given values makes the board win.
+func (b *Board) completedBy(drawn map[int]bool) bool {
+	for _, line := range b.lines() {
+		completed := true
+
+		for _, value := range line {
+			if !drawn[value] {
+				completed = false
+				break
+			}
+		}
+
+		if completed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// FindWinningSequence finds the shortest sequence of values drawn from the pool that makes board with index target win
+// strictly before every other board, taking current marks of the boards into account. ErrNoWinningSequence is
+// returned when no such sequence exists.
+//
+// Shortest sequence always consists of the unmarked values of a single row or column of the target board, so every
+// line is tried and the shortest one that does not also complete a line of another board is chosen. The result is
+// verified by playing it on copies of the boards.
+func FindWinningSequence(boards []*Board, target int, pool []int) ([]int, error) {
+	if target < 0 || target >= len(boards) {
+		return nil, errors.New(fmt.Sprintf("target board index %d out of range [0, %d)", target, len(boards)))
+	}
+
+	for i, board := range boards {
+		if board.Won() {
+			return nil, errors.New(fmt.Sprintf("board %d already won", i+1))
+		}
+	}
+
+	inPool := make(map[int]bool)
+	for _, value := range pool {
+		inPool[value] = true
+	}
+
+	var best []int
+
+	for _, line := range boards[target].lines() {
+		drawn := make(map[int]bool)
+		var sequence []int
+		feasible := true
+
+		for _, value := range line {
+			if !inPool[value] {
+				feasible = false
+				break
+			}
+
+			if !drawn[value] {
+				drawn[value] = true
+				sequence = append(sequence, value)
+			}
+		}
+
+		if !feasible || (best != nil && len(sequence) >= len(best)) {
+			continue
+		}
+
+		for i, board := range boards {
+			if i != target && board.completedBy(drawn) {
+				feasible = false
+				break
+			}
+		}
+
+		if feasible {
+			best = sequence
+		}
+	}
+
+	if best == nil {
+		return nil, ErrNoWinningSequence
+	}
+
+	// Verify the sequence with regular game rules.
+	ranking, err := NewGame(best, boards).Play()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ranking) != 1 || ranking[0].BoardIdx != target || ranking[0].DrawIdx != len(best)-1 {
+		return nil, errors.New(fmt.Sprintf("sequence %v does not make board %d win first", best, target+1))
+	}
+
+	return best, nil
+}
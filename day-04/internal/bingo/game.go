@@ -0,0 +1,151 @@
+package bingo
+
+import (
+	"errors"
+	"fmt"
+)
+
+// WinRecord describes when and how a board won.
+type WinRecord struct {
+	BoardIdx int `json:"board"`
+	DrawIdx  int `json:"draw"`
+	Value    int `json:"value"`
+	Score    int `json:"score"`
+}
+
+// Event is emitted for every drawn number.
+type Event struct {
+	DrawIdx int
+	Value   int
+	// Winners contains win records of the boards that won with this draw.
+	Winners []WinRecord
+}
+
+// EventHandler is invoked after every draw of the Game.
+type EventHandler func(e Event)
+
+// Game owns the draw sequence and the boards and plays bingo until every board wins or the sequence runs out.
+type Game struct {
+	sequence []int
+	boards   []*Board
+	drawIdx  int
+	ranking  []WinRecord
+	handler  EventHandler
+}
+
+// NewGame creates a new Game. Boards are copied, so the provided boards are never modified by the Game.
+func NewGame(sequence []int, boards []*Board) *Game {
+	g := Game{sequence: make([]int, len(sequence)), boards: make([]*Board, len(boards))}
+	copy(g.sequence, sequence)
+
+	for i, board := range boards {
+		g.boards[i] = board.Clone()
+	}
+
+	return &g
+}
+
+// OnDraw registers handler that is invoked after every draw. Passing nil removes the handler.
+func (g *Game) OnDraw(handler EventHandler) {
+	g.handler = handler
+}
+
+// Draw draws the next number from the sequence and marks it on all boards that did not win yet. Returns false when
+// there is nothing left to draw.
+func (g *Game) Draw() (bool, error) {
+	if g.drawIdx >= len(g.sequence) {
+		return false, nil
+	}
+
+	value := g.sequence[g.drawIdx]
+	event := Event{DrawIdx: g.drawIdx, Value: value}
+
+	for i, board := range g.boards {
+		if board.Won() {
+			continue
+		}
+
+		won, err := board.MarkValue(value)
+
+		if err != nil {
+			return false, errors.New(fmt.Sprintf("failed to mark value %d on board %d (%s)", value, i+1, err.Error()))
+		}
+
+		if won {
+			record := WinRecord{BoardIdx: i, DrawIdx: g.drawIdx, Value: value, Score: board.Score()}
+			g.ranking = append(g.ranking, record)
+			event.Winners = append(event.Winners, record)
+		}
+	}
+
+	g.drawIdx++
+
+	if g.handler != nil {
+		g.handler(event)
+	}
+
+	return true, nil
+}
+
+// Play draws numbers until every board has won or the sequence is exhausted and returns the ranking.
+func (g *Game) Play() ([]WinRecord, error) {
+	for !g.Finished() {
+		more, err := g.Draw()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !more {
+			break
+		}
+	}
+
+	return g.Ranking(), nil
+}
+
+// Finished reports whether every board has won.
+func (g *Game) Finished() bool {
+	return len(g.ranking) == len(g.boards)
+}
+
+// Ranking returns win records of the boards that won so far, ordered by draw index and then by board index.
+func (g *Game) Ranking() []WinRecord {
+	ranking := make([]WinRecord, len(g.ranking))
+	copy(ranking, g.ranking)
+
+	return ranking
+}
+
+// DrawCount returns the number of values drawn so far.
+func (g *Game) DrawCount() int {
+	return g.drawIdx
+}
+
+// Sequence returns a copy of the draw sequence.
+func (g *Game) Sequence() []int {
+	sequence := make([]int, len(g.sequence))
+	copy(sequence, g.sequence)
+
+	return sequence
+}
+
+// Board returns the game's copy of the board with the given index.
+func (g *Game) Board(idx int) *Board {
+	return g.boards[idx]
+}
+
+// BoardCount returns the number of boards in the game.
+func (g *Game) BoardCount() int {
+	return len(g.boards)
+}
+
+// Reset unmarks all boards and rewinds the draw sequence.
+func (g *Game) Reset() {
+	for _, board := range g.boards {
+		board.Reset()
+	}
+
+	g.drawIdx = 0
+	g.ranking = nil
+}
@@ -0,0 +1,267 @@
+package bingo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// maxCardAttempts number of random cards tried before generator gives up on satisfying the constraints.
+const maxCardAttempts = 100000
+
+// GeneratorConfig configures Generate.
+type GeneratorConfig struct {
+	// Cards number of unique cards to generate.
+	Cards int
+	// PoolSize number of values in the pool, values are 0 ... PoolSize-1.
+	PoolSize int
+	// Size number of rows and columns of a card.
+	Size int
+	Seed int64
+	// NoWinBefore if positive, no card wins before this draw (1-based).
+	NoWinBefore int
+	// SingleWinnerAt if positive, exactly one card wins on this draw (1-based) and no card wins before it, so the card
+	// is the single winner of the game.
+	SingleWinnerAt int
+}
+
+// GeneratedInput contains generated draw sequence and cards.
+type GeneratedInput struct {
+	Sequence []int
+	Cards    [][][]int
+}
+
+// generator holds state of a single Generate call.
+type generator struct {
+	cfg      GeneratorConfig
+	rng      *rand.Rand
+	sequence []int
+	rank     map[int]int
+	seen     map[string]bool
+}
+
+// Generate generates a shuffled draw sequence of the whole pool and cards that satisfy the configured constraints.
+func Generate(cfg GeneratorConfig) (*GeneratedInput, error) {
+	if cfg.Size < 1 || cfg.Cards < 0 {
+		return nil, errors.New(fmt.Sprintf("invalid card size %d or card count %d", cfg.Size, cfg.Cards))
+	}
+
+	if cfg.PoolSize < cfg.Size*cfg.Size {
+		return nil, errors.New(fmt.Sprintf("pool of %d values is too small for %dx%d cards", cfg.PoolSize, cfg.Size,
+			cfg.Size))
+	}
+
+	if cfg.SingleWinnerAt > cfg.PoolSize || cfg.NoWinBefore > cfg.PoolSize+1 {
+		return nil, errors.New(fmt.Sprintf("constraint draws must not exceed pool size %d", cfg.PoolSize))
+	}
+
+	if cfg.SingleWinnerAt > 0 && (cfg.SingleWinnerAt < cfg.Size || cfg.SingleWinnerAt < cfg.NoWinBefore) {
+		return nil, errors.New(fmt.Sprintf("no card can win on draw %d", cfg.SingleWinnerAt))
+	}
+
+	if cfg.SingleWinnerAt > 0 && cfg.Cards == 0 {
+		return nil, errors.New("single winner constraint requires at least one card")
+	}
+
+	g := generator{cfg: cfg, rng: rand.New(rand.NewSource(cfg.Seed)), rank: make(map[int]int), seen: make(map[string]bool)}
+	g.sequence = g.rng.Perm(cfg.PoolSize)
+	input := GeneratedInput{Sequence: g.sequence}
+
+	for i, value := range g.sequence {
+		g.rank[value] = i + 1
+	}
+
+	if cfg.SingleWinnerAt > 0 {
+		card, err := g.generateCard(g.winnerCard, true)
+
+		if err != nil {
+			return nil, err
+		}
+
+		input.Cards = append(input.Cards, card)
+	}
+
+	for len(input.Cards) < cfg.Cards {
+		card, err := g.generateCard(g.randomCard, false)
+
+		if err != nil {
+			return nil, err
+		}
+
+		input.Cards = append(input.Cards, card)
+	}
+
+	// Do not give away the winner by its position.
+	g.rng.Shuffle(len(input.Cards), func(a, b int) { input.Cards[a], input.Cards[b] = input.Cards[b], input.Cards[a] })
+
+	return &input, nil
+}
+
+// generateCard draws candidate cards until one is unique and satisfies the constraints. Only the winner card may win on
+// or before the SingleWinnerAt draw.
+func (g *generator) generateCard(candidate func() [][]int, winner bool) ([][]int, error) {
+	for attempt := 0; attempt < maxCardAttempts; attempt++ {
+		card := candidate()
+		key := fmt.Sprint(card)
+
+		if g.seen[key] || !g.satisfiesConstraints(card, winner) {
+			continue
+		}
+
+		g.seen[key] = true
+
+		return card, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("could not generate a unique card satisfying constraints in %d attempts",
+		maxCardAttempts))
+}
+
+// satisfiesConstraints checks whether the card may be added.
+func (g *generator) satisfiesConstraints(card [][]int, winner bool) bool {
+	draw := gridWinDraw(card, g.rank)
+
+	if draw < g.cfg.NoWinBefore {
+		return false
+	}
+
+	if g.cfg.SingleWinnerAt > 0 {
+		if winner {
+			return draw == g.cfg.SingleWinnerAt
+		}
+
+		return draw == 0 || draw > g.cfg.SingleWinnerAt
+	}
+
+	return true
+}
+
+// sample returns k distinct random values of 0 ... n-1. It performs the first k steps of a Fisher-Yates shuffle on a
+// virtual identity permutation, storing only the swapped entries, so it takes O(k) time regardless of n.
+func (g *generator) sample(n, k int) []int {
+	swapped := make(map[int]int)
+	values := make([]int, k)
+
+	valueAt := func(i int) int {
+		if value, ok := swapped[i]; ok {
+			return value
+		}
+
+		return i
+	}
+
+	for i := 0; i < k; i++ {
+		j := i + g.rng.Intn(n-i)
+		values[i] = valueAt(j)
+		swapped[j] = valueAt(i)
+	}
+
+	return values
+}
+
+// randomCard creates card of random distinct pool values.
+func (g *generator) randomCard() [][]int {
+	return g.fillCard(g.sample(g.cfg.PoolSize, g.cfg.Size*g.cfg.Size))
+}
+
+// winnerCard creates card with a random line completed by the SingleWinnerAt draw.
+func (g *generator) winnerCard() [][]int {
+	size := g.cfg.Size
+	winIdx := g.cfg.SingleWinnerAt - 1
+
+	// Line holds the winning value and size-1 values drawn before it.
+	values := append([]int{winIdx}, g.sample(winIdx, size-1)...)
+
+	// Remaining cells hold any other values. At most size of the size*size sampled values are already used.
+	used := make(map[int]bool)
+	for _, idx := range values {
+		used[idx] = true
+	}
+
+	for _, idx := range g.sample(g.cfg.PoolSize, size*size) {
+		if len(values) == size*size {
+			break
+		}
+
+		if !used[idx] {
+			values = append(values, idx)
+		}
+	}
+
+	// Convert sequence indices to values and shuffle the line.
+	for i, idx := range values {
+		values[i] = g.sequence[idx]
+	}
+
+	g.rng.Shuffle(size, func(a, b int) { values[a], values[b] = values[b], values[a] })
+	card := g.fillCard(values)
+
+	// Place the winning line at a random row or column.
+	line := g.rng.Intn(size)
+	if line != 0 {
+		card[0], card[line] = card[line], card[0]
+	}
+
+	if g.rng.Intn(2) == 1 {
+		for i := 0; i < size; i++ {
+			for j := i + 1; j < size; j++ {
+				card[i][j], card[j][i] = card[j][i], card[i][j]
+			}
+		}
+	}
+
+	return card
+}
+
+// fillCard arranges values row by row into a card.
+func (g *generator) fillCard(values []int) [][]int {
+	card := make([][]int, g.cfg.Size)
+
+	for i := range card {
+		card[i] = make([]int, g.cfg.Size)
+		copy(card[i], values[i*g.cfg.Size:])
+	}
+
+	return card
+}
+
+// Write writes the generated input in the format read by Parse.
+func (input *GeneratedInput) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+
+	width := 1
+	for _, value := range input.Sequence {
+		if l := len(strconv.Itoa(value)); l > width {
+			width = l
+		}
+	}
+
+	strSequence := make([]string, len(input.Sequence))
+	for i, value := range input.Sequence {
+		strSequence[i] = strconv.Itoa(value)
+	}
+
+	bw.WriteString(strings.Join(strSequence, ","))
+	bw.WriteString("\n")
+
+	for _, card := range input.Cards {
+		bw.WriteString("\n")
+
+		for _, row := range card {
+			strRow := make([]string, len(row))
+
+			for i, value := range row {
+				strRow[i] = fmt.Sprintf("%*d", width, value)
+			}
+
+			bw.WriteString(strings.Join(strRow, " "))
+			bw.WriteString("\n")
+		}
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,335 @@
+package bingo
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sync"
+)
+
+// MaxExactPoolSize is the largest number pool for which ExactWinProbabilities enumerates all draw orders.
+const MaxExactPoolSize = 10
+
+// trialsPerChunk number of trials that share a single RNG. Chunks are seeded independently of the worker count, so
+// results only depend on the seed.
+const trialsPerChunk = 1024
+
+// confidenceZ z-score of the reported 95% confidence intervals.
+const confidenceZ = 1.959964
+
+// Estimate is a probability estimate together with its 95% confidence interval.
+type Estimate struct {
+	P    float64
+	Low  float64
+	High float64
+}
+
+// CardStats contains win statistics of a single board.
+type CardStats struct {
+	// WinFirst probability that the board is the first to win. Boards that tie for first place share the win equally.
+	WinFirst Estimate
+	// DrawsToWin[k] is the probability that the board wins on the k-th draw. DrawsToWin[0] is the probability that the
+	// board never wins, because it holds values that are not in the pool.
+	DrawsToWin []float64
+}
+
+// WinProbabilities contains statistics of every board, indexed the same as the input boards.
+type WinProbabilities struct {
+	Trials int
+	Cards  []CardStats
+}
+
+// MonteCarloConfig configures EstimateWinProbabilities.
+type MonteCarloConfig struct {
+	// Pool of values that are drawn in random order in every trial.
+	Pool   []int
+	Trials int
+	Seed   int64
+	// Workers number of goroutines, defaults to runtime.NumCPU() when zero.
+	Workers int
+}
+
+// winTally accumulates per-board results of a batch of trials.
+type winTally struct {
+	trials int
+	// firstSum and firstSumSq sums of (squared) per-trial win-first credit.
+	firstSum   []float64
+	firstSumSq []float64
+	drawCounts [][]int
+}
+
+func newWinTally(boardCount int, poolSize int) *winTally {
+	t := winTally{firstSum: make([]float64, boardCount), firstSumSq: make([]float64, boardCount),
+		drawCounts: make([][]int, boardCount)}
+
+	for i := range t.drawCounts {
+		t.drawCounts[i] = make([]int, poolSize+1)
+	}
+
+	return &t
+}
+
+// add records a single trial given the draw count on which every board won (0 if it never won).
+func (t *winTally) add(winDraws []int) {
+	firstDraw := 0
+	tied := 0
+
+	for _, draw := range winDraws {
+		if draw == 0 {
+			continue
+		}
+
+		if firstDraw == 0 || draw < firstDraw {
+			firstDraw = draw
+			tied = 1
+		} else if draw == firstDraw {
+			tied++
+		}
+	}
+
+	for i, draw := range winDraws {
+		t.drawCounts[i][draw]++
+
+		if draw != 0 && draw == firstDraw {
+			credit := 1 / float64(tied)
+			t.firstSum[i] += credit
+			t.firstSumSq[i] += credit * credit
+		}
+	}
+
+	t.trials++
+}
+
+// merge adds results of other tally to this one.
+func (t *winTally) merge(other *winTally) {
+	for i := range t.firstSum {
+		t.firstSum[i] += other.firstSum[i]
+		t.firstSumSq[i] += other.firstSumSq[i]
+
+		for j := range t.drawCounts[i] {
+			t.drawCounts[i][j] += other.drawCounts[i][j]
+		}
+	}
+
+	t.trials += other.trials
+}
+
+// result converts tally to probabilities. Confidence intervals are computed with normal approximation unless exact is
+// set, in which case tally covers the whole population and intervals have zero width.
+func (t *winTally) result(exact bool) *WinProbabilities {
+	res := WinProbabilities{Trials: t.trials, Cards: make([]CardStats, len(t.firstSum))}
+	n := float64(t.trials)
+
+	for i := range res.Cards {
+		card := &res.Cards[i]
+		mean := t.firstSum[i] / n
+		card.WinFirst = Estimate{P: mean, Low: mean, High: mean}
+
+		if !exact && t.trials > 1 {
+			variance := (t.firstSumSq[i] - n*mean*mean) / (n - 1)
+			halfWidth := confidenceZ * math.Sqrt(math.Max(variance, 0)/n)
+			card.WinFirst.Low = math.Max(0, mean-halfWidth)
+			card.WinFirst.High = math.Min(1, mean+halfWidth)
+		}
+
+		card.DrawsToWin = make([]float64, len(t.drawCounts[i]))
+		for j, count := range t.drawCounts[i] {
+			card.DrawsToWin[j] = float64(count) / n
+		}
+	}
+
+	return &res
+}
+
+// winDrawCounter computes on which draw each board wins for a given draw order.
+type winDrawCounter struct {
+	boards []*Board
+	rank   map[int]int
+	draws  []int
+}
+
+func newWinDrawCounter(boards []*Board) *winDrawCounter {
+	return &winDrawCounter{boards: boards, rank: make(map[int]int), draws: make([]int, len(boards))}
+}
+
+// count returns draw counts (1-based) on which each board wins when values are drawn in the given order or 0 if the
+// board never wins. Returned slice is reused between calls.
+func (c *winDrawCounter) count(order []int) []int {
+	for k := range c.rank {
+		delete(c.rank, k)
+	}
+
+	for i, value := range order {
+		if _, ok := c.rank[value]; !ok {
+			c.rank[value] = i + 1
+		}
+	}
+
+	for i, board := range c.boards {
+		c.draws[i] = board.winDraw(c.rank)
+	}
+
+	return c.draws
+}
+
+// winDraw returns the draw count on which the board wins if values are drawn with the given ranks, or 0 if it never
+// wins. Marks of the board are ignored.
+func (b *Board) winDraw(rank map[int]int) int {
+	return winDrawOf(b.Size(), func(row int, column int) int { return b.grid[row][column].value }, rank)
+}
+
+// gridWinDraw returns the draw count on which a square card with the given values wins, or 0 if it never wins.
+func gridWinDraw(card [][]int, rank map[int]int) int {
+	return winDrawOf(len(card), func(row int, column int) int { return card[row][column] }, rank)
+}
+
+// winDrawOf returns the earliest draw count on which any row or column of a size x size grid is completed.
+func winDrawOf(size int, valueAt func(row int, column int) int, rank map[int]int) int {
+	best := 0
+
+	for i := 0; i < size; i++ {
+		rowDraw, columnDraw := 0, 0
+
+		for j := 0; j < size; j++ {
+			rowDraw = lineDraw(rowDraw, rank, valueAt(i, j))
+			columnDraw = lineDraw(columnDraw, rank, valueAt(j, i))
+		}
+
+		for _, draw := range [2]int{rowDraw, columnDraw} {
+			if draw > 0 && (best == 0 || draw < best) {
+				best = draw
+			}
+		}
+	}
+
+	return best
+}
+
+// lineDraw folds value into the draw on which a line completes (-1 marks a line that never completes).
+func lineDraw(current int, rank map[int]int, value int) int {
+	if current < 0 {
+		return current
+	}
+
+	r, ok := rank[value]
+
+	if !ok {
+		return -1
+	}
+
+	if r > current {
+		return r
+	}
+
+	return current
+}
+
+// EstimateWinProbabilities estimates each board's probability to win first and its distribution of draws until the
+// win by playing cfg.Trials games with uniformly random draw orders of the pool.
+func EstimateWinProbabilities(boards []*Board, cfg MonteCarloConfig) (*WinProbabilities, error) {
+	if len(boards) == 0 {
+		return nil, errors.New("cannot estimate win probabilities without boards")
+	}
+
+	if cfg.Trials <= 0 {
+		return nil, errors.New(fmt.Sprintf("invalid trial count %d, must be positive", cfg.Trials))
+	}
+
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	chunkCount := (cfg.Trials + trialsPerChunk - 1) / trialsPerChunk
+	chunkTallies := make([]*winTally, chunkCount)
+	chunks := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			counter := newWinDrawCounter(boards)
+			order := make([]int, len(cfg.Pool))
+
+			for chunk := range chunks {
+				rng := rand.New(rand.NewSource(cfg.Seed + int64(chunk)))
+				tally := newWinTally(len(boards), len(cfg.Pool))
+
+				trials := trialsPerChunk
+				if remaining := cfg.Trials - chunk*trialsPerChunk; remaining < trials {
+					trials = remaining
+				}
+
+				for i := 0; i < trials; i++ {
+					copy(order, cfg.Pool)
+					rng.Shuffle(len(order), func(a, b int) { order[a], order[b] = order[b], order[a] })
+					tally.add(counter.count(order))
+				}
+
+				chunkTallies[chunk] = tally
+			}
+		}()
+	}
+
+	for chunk := 0; chunk < chunkCount; chunk++ {
+		chunks <- chunk
+	}
+
+	close(chunks)
+	wg.Wait()
+
+	// Merge in chunk order so that floating point sums do not depend on scheduling.
+	total := newWinTally(len(boards), len(cfg.Pool))
+	for _, tally := range chunkTallies {
+		total.merge(tally)
+	}
+
+	return total.result(false), nil
+}
+
+// ExactWinProbabilities computes exact win probabilities by enumerating every draw order of the pool. Pool may hold at
+// most MaxExactPoolSize values.
+func ExactWinProbabilities(boards []*Board, pool []int) (*WinProbabilities, error) {
+	if len(boards) == 0 {
+		return nil, errors.New("cannot compute win probabilities without boards")
+	}
+
+	if len(pool) > MaxExactPoolSize {
+		return nil, errors.New(fmt.Sprintf("pool of %d values is too large for exact mode, at most %d allowed",
+			len(pool), MaxExactPoolSize))
+	}
+
+	counter := newWinDrawCounter(boards)
+	tally := newWinTally(len(boards), len(pool))
+	order := make([]int, len(pool))
+	copy(order, pool)
+
+	// Heap's algorithm.
+	c := make([]int, len(order))
+	tally.add(counter.count(order))
+
+	for i := 1; i < len(order); {
+		if c[i] < i {
+			if i%2 == 0 {
+				order[0], order[i] = order[i], order[0]
+			} else {
+				order[c[i]], order[i] = order[i], order[c[i]]
+			}
+
+			tally.add(counter.count(order))
+			c[i]++
+			i = 1
+		} else {
+			c[i] = 0
+			i++
+		}
+	}
+
+	return tally.result(true), nil
+}
@@ -0,0 +1,208 @@
+package bingo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// lineReader reads lines of any length and keeps track of the current line number.
+type lineReader struct {
+	reader  *bufio.Reader
+	lineNum int
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{reader: bufio.NewReader(r)}
+}
+
+// next returns the next line with surrounding whitespace trimmed. Returns false on EOF. Unlike bufio.Scanner, lines
+// are not limited in length, so sequences of large pools can be read.
+func (lr *lineReader) next() (string, bool, error) {
+	line, err := lr.reader.ReadString('\n')
+
+	if err == io.EOF {
+		if line == "" {
+			return "", false, nil
+		}
+	} else if err != nil {
+		return "", false, err
+	}
+
+	lr.lineNum++
+
+	return strings.TrimSpace(line), true, nil
+}
+
+// errorf creates error annotated with the current line number.
+func (lr *lineReader) errorf(format string, a ...interface{}) error {
+	return errors.New(fmt.Sprintf("line %d: %s", lr.lineNum, fmt.Sprintf(format, a...)))
+}
+
+// parseSequenceLine parses comma separated values, whitespace around the values is ignored.
+func (lr *lineReader) parseSequenceLine(line string) ([]int, error) {
+	var sequence []int
+
+	for i, strEntry := range strings.Split(line, ",") {
+		strEntry = strings.TrimSpace(strEntry)
+
+		// Allow trailing comma.
+		if strEntry == "" && i > 0 && i == strings.Count(line, ",") {
+			break
+		}
+
+		entry, err := strconv.Atoi(strEntry)
+
+		if err != nil {
+			return nil, lr.errorf("could not parse %d-th element of bingo sequence (%s)", i+1, err.Error())
+		}
+
+		sequence = append(sequence, entry)
+	}
+
+	return sequence, nil
+}
+
+// nextNonEmpty skips blank lines and returns the first non-blank one. Returns false on EOF.
+func (lr *lineReader) nextNonEmpty() (string, bool, error) {
+	for {
+		line, ok, err := lr.next()
+
+		if !ok || err != nil || line != "" {
+			return line, ok, err
+		}
+	}
+}
+
+// parseBoards parses boards until EOF. Boards are separated by one or more blank lines and the last board does not
+// need to be followed by one. Boards are square, their size is given by the number of values in the first row.
+func (lr *lineReader) parseBoards() ([]*Board, error) {
+	var boards []*Board
+	var boardValues [][]int
+
+	boardStart := 0
+
+	for {
+		line, ok, err := lr.next()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok || line == "" {
+			if len(boardValues) > 0 && len(boardValues) == len(boardValues[0]) {
+				boards = append(boards, newBoard(boardValues))
+			} else if len(boardValues) != 0 {
+				return nil, errors.New(fmt.Sprintf("line %d: incomplete bingo board, has %d rows, expected %d",
+					boardStart, len(boardValues), len(boardValues[0])))
+			}
+
+			boardValues = nil
+
+			if !ok {
+				return boards, nil
+			}
+
+			continue
+		}
+
+		rowValues := strings.Fields(line)
+		size := len(rowValues)
+
+		if len(boardValues) == 0 {
+			boardStart = lr.lineNum
+		} else {
+			size = len(boardValues[0])
+		}
+
+		if len(boardValues) == size {
+			return nil, lr.errorf("bingo board starting on line %d has more than %d rows", boardStart, size)
+		}
+
+		if len(rowValues) != size {
+			return nil, lr.errorf("invalid column count %d, expected %d", len(rowValues), size)
+		}
+
+		row := make([]int, size)
+
+		for i := range row {
+			value, err := strconv.Atoi(rowValues[i])
+
+			if err != nil {
+				return nil, lr.errorf("could not parse %d-th value of bingo board row (%s)", i+1, err.Error())
+			}
+
+			row[i] = value
+		}
+
+		boardValues = append(boardValues, row)
+	}
+}
+
+// Parse reads a bingo file consisting of the comma separated draw sequence followed by blank line separated boards.
+func Parse(r io.Reader) ([]int, []*Board, error) {
+	lr := newLineReader(r)
+	line, ok, err := lr.nextNonEmpty()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if !ok {
+		return nil, nil, errors.New("bad file format, file contains no data")
+	}
+
+	sequence, err := lr.parseSequenceLine(line)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	boards, err := lr.parseBoards()
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return sequence, boards, nil
+}
+
+// ParseSequence reads a comma separated draw sequence that may span multiple lines.
+func ParseSequence(r io.Reader) ([]int, error) {
+	lr := newLineReader(r)
+	var sequence []int
+
+	for {
+		line, ok, err := lr.nextNonEmpty()
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !ok {
+			break
+		}
+
+		values, err := lr.parseSequenceLine(line)
+
+		if err != nil {
+			return nil, err
+		}
+
+		sequence = append(sequence, values...)
+	}
+
+	if len(sequence) == 0 {
+		return nil, errors.New("bad sequence format, sequence contains no data")
+	}
+
+	return sequence, nil
+}
+
+// ParseBoards reads blank line separated boards without a preceding draw sequence.
+func ParseBoards(r io.Reader) ([]*Board, error) {
+	return newLineReader(r).parseBoards()
+}
@@ -0,0 +1,294 @@
+package bingo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// boardJSON is the JSON representation of a Board.
+type boardJSON struct {
+	Values       [][]int  `json:"values"`
+	Marked       [][]bool `json:"marked"`
+	Won          bool     `json:"won"`
+	WinningValue int      `json:"winning_value"`
+	Score        int      `json:"score"`
+}
+
+// String renders the board grid with marked values enclosed in square brackets. Columns are as wide as the widest
+// value, but at least two characters.
+func (b *Board) String() string {
+	var sb strings.Builder
+
+	width := 2
+
+	for i := range b.grid {
+		for j := range b.grid[i] {
+			if w := len(strconv.Itoa(b.grid[i][j].value)); w > width {
+				width = w
+			}
+		}
+	}
+
+	for i := range b.grid {
+		for j := range b.grid[i] {
+			field := &b.grid[i][j]
+
+			if j > 0 {
+				sb.WriteByte(' ')
+			}
+
+			if field.marked {
+				sb.WriteString(fmt.Sprintf("[%*d]", width, field.value))
+			} else {
+				sb.WriteString(fmt.Sprintf(" %*d ", width, field.value))
+			}
+		}
+
+		sb.WriteByte('\n')
+	}
+
+	return sb.String()
+}
+
+// MarshalJSON encodes values, marks, won state and score of the board.
+func (b *Board) MarshalJSON() ([]byte, error) {
+	bj := boardJSON{Values: make([][]int, b.Size()), Marked: make([][]bool, b.Size()), Won: b.won,
+		WinningValue: b.winningValue, Score: b.score}
+
+	for i := range b.grid {
+		bj.Values[i] = make([]int, b.Size())
+		bj.Marked[i] = make([]bool, b.Size())
+
+		for j := range b.grid[i] {
+			bj.Values[i][j] = b.grid[i][j].value
+			bj.Marked[i][j] = b.grid[i][j].marked
+		}
+	}
+
+	return json.Marshal(bj)
+}
+
+// UnmarshalJSON decodes board encoded by MarshalJSON. Won state must be consistent with the marks and score of a won
+// board must match the score recomputed from the marks and the winning value.
+func (b *Board) UnmarshalJSON(data []byte) error {
+	var bj boardJSON
+
+	if err := json.Unmarshal(data, &bj); err != nil {
+		return err
+	}
+
+	decodedBoard, err := NewSquareBoard(bj.Values)
+
+	if err != nil {
+		return err
+	}
+
+	decoded := *decodedBoard
+
+	if len(bj.Marked) != len(bj.Values) {
+		return errors.New(fmt.Sprintf("inconsistent board, has %d rows of marks, expected %d", len(bj.Marked),
+			len(bj.Values)))
+	}
+
+	for i, row := range bj.Marked {
+		if len(row) != len(bj.Values) {
+			return errors.New(fmt.Sprintf("inconsistent board, row %d has %d marks, expected %d", i+1, len(row),
+				len(bj.Values)))
+		}
+
+		for j, marked := range row {
+			decoded.grid[i][j].marked = marked
+		}
+	}
+
+	if bj.Won != decoded.hasCompleteLine() {
+		return errors.New(fmt.Sprintf("inconsistent board, won state is %t but marks say otherwise", bj.Won))
+	}
+
+	if !bj.Won && (bj.Score != 0 || bj.WinningValue != 0) {
+		return errors.New(fmt.Sprintf("inconsistent board, board has not won but has score %d and winning value %d",
+			bj.Score, bj.WinningValue))
+	}
+
+	if bj.Won {
+		if !decoded.completesLine(bj.WinningValue) {
+			return errors.New(fmt.Sprintf("inconsistent board, winning value %d does not complete a line",
+				bj.WinningValue))
+		}
+
+		if score := decoded.computeScore(bj.WinningValue); score != bj.Score {
+			return errors.New(fmt.Sprintf("inconsistent board, score is %d but marks and winning value give %d",
+				bj.Score, score))
+		}
+	}
+
+	decoded.won = bj.Won
+	decoded.winningValue = bj.WinningValue
+	decoded.score = bj.Score
+	*b = decoded
+
+	return nil
+}
+
+// hasCompleteLine reports whether any row or column of the board is fully marked.
+func (b *Board) hasCompleteLine() bool {
+	for i := range b.grid {
+		if b.checkWinningCondition(i, i) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// completesLine reports whether the value is marked on a fully marked row or column.
+func (b *Board) completesLine(value int) bool {
+	for i := range b.grid {
+		for j := range b.grid[i] {
+			if b.grid[i][j].value == value && b.grid[i][j].marked && b.checkWinningCondition(i, j) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// GameSnapshot captures the state of a Game so that it can be persisted and resumed with RestoreGame.
+type GameSnapshot struct {
+	Sequence  []int       `json:"sequence"`
+	DrawCount int         `json:"draw_count"`
+	Boards    []*Board    `json:"boards"`
+	Ranking   []WinRecord `json:"ranking"`
+}
+
+// Snapshot returns a copy of the current game state. Registered event handler is not part of the snapshot.
+func (g *Game) Snapshot() GameSnapshot {
+	s := GameSnapshot{Sequence: g.Sequence(), DrawCount: g.drawIdx, Boards: make([]*Board, len(g.boards)),
+		Ranking: g.Ranking()}
+
+	for i, board := range g.boards {
+		s.Boards[i] = board.Clone()
+	}
+
+	return s
+}
+
+// RestoreGame creates a Game from the snapshot after validating it. Snapshot must be consistent with replaying its
+// drawn values on unmarked copies of its boards: ranking values must be the drawn values, ranking must be ordered by
+// draw and marks of every board must match the values drawn until it won.
+func RestoreGame(s GameSnapshot) (*Game, error) {
+	if s.DrawCount < 0 || s.DrawCount > len(s.Sequence) {
+		return nil, errors.New(fmt.Sprintf("invalid snapshot, draw count %d out of range [0, %d]", s.DrawCount,
+			len(s.Sequence)))
+	}
+
+	ranked := make(map[int]bool)
+	records := make(map[int]WinRecord)
+
+	for i, record := range s.Ranking {
+		if record.BoardIdx < 0 || record.BoardIdx >= len(s.Boards) || ranked[record.BoardIdx] {
+			return nil, errors.New(fmt.Sprintf("invalid snapshot, bad board index %d in ranking", record.BoardIdx))
+		}
+
+		if record.DrawIdx < 0 || record.DrawIdx >= s.DrawCount {
+			return nil, errors.New(fmt.Sprintf("invalid snapshot, ranking draw index %d not yet drawn", record.DrawIdx))
+		}
+
+		if record.Value != s.Sequence[record.DrawIdx] {
+			return nil, errors.New(fmt.Sprintf("invalid snapshot, ranking value %d of board %d was not drawn on draw %d",
+				record.Value, record.BoardIdx+1, record.DrawIdx+1))
+		}
+
+		if i > 0 {
+			prev := s.Ranking[i-1]
+
+			if record.DrawIdx < prev.DrawIdx || (record.DrawIdx == prev.DrawIdx && record.BoardIdx < prev.BoardIdx) {
+				return nil, errors.New(fmt.Sprintf("invalid snapshot, ranking is not ordered by draw at entry %d", i+1))
+			}
+		}
+
+		ranked[record.BoardIdx] = true
+		records[record.BoardIdx] = record
+	}
+
+	for i, board := range s.Boards {
+		if board == nil {
+			return nil, errors.New(fmt.Sprintf("invalid snapshot, board %d is missing", i+1))
+		}
+
+		if board.Won() != ranked[i] {
+			return nil, errors.New(fmt.Sprintf("invalid snapshot, won state of board %d does not match ranking", i+1))
+		}
+
+		if record, ok := records[i]; ok && (record.Score != board.Score() || record.Value != board.winningValue) {
+			return nil, errors.New(fmt.Sprintf("invalid snapshot, score of board %d does not match ranking", i+1))
+		}
+	}
+
+	if err := checkReplay(s); err != nil {
+		return nil, err
+	}
+
+	g := NewGame(s.Sequence, s.Boards)
+	g.drawIdx = s.DrawCount
+	g.ranking = make([]WinRecord, len(s.Ranking))
+	copy(g.ranking, s.Ranking)
+
+	return g, nil
+}
+
+// checkReplay replays the drawn values of the snapshot on unmarked copies of its boards and compares the outcome with
+// the snapshot.
+func checkReplay(s GameSnapshot) error {
+	replay := NewGame(s.Sequence, s.Boards)
+	replay.Reset()
+
+	for replay.DrawCount() < s.DrawCount {
+		if _, err := replay.Draw(); err != nil {
+			return err
+		}
+	}
+
+	for i, board := range s.Boards {
+		if !board.sameMarks(replay.Board(i)) {
+			return errors.New(fmt.Sprintf("invalid snapshot, marks of board %d do not match the drawn values", i+1))
+		}
+	}
+
+	ranking := replay.Ranking()
+
+	if len(ranking) != len(s.Ranking) {
+		return errors.New(fmt.Sprintf("invalid snapshot, ranking has %d entries, but replay gives %d", len(s.Ranking),
+			len(ranking)))
+	}
+
+	for i := range ranking {
+		if ranking[i] != s.Ranking[i] {
+			return errors.New(fmt.Sprintf("invalid snapshot, ranking entry %d is %+v, but replay gives %+v", i+1,
+				s.Ranking[i], ranking[i]))
+		}
+	}
+
+	return nil
+}
+
+// sameMarks reports whether both boards have the same values and marks.
+func (b *Board) sameMarks(other *Board) bool {
+	if b.Size() != other.Size() {
+		return false
+	}
+
+	for i := range b.grid {
+		for j := range b.grid[i] {
+			if b.grid[i][j] != other.grid[i][j] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
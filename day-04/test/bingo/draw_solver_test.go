@@ -0,0 +1,59 @@
+package test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-4/internal/bingo"
+)
+
+func TestFindWinningSequence(t *testing.T) {
+	boards := exampleBoards()
+
+	for target := range boards {
+		sequence, err := bingo.FindWinningSequence(boards, target, exampleSequence)
+
+		if err != nil {
+			t.Fatalf("encountered error (%s) for target %d", err.Error(), target)
+		}
+
+		if len(sequence) != bingo.BoardSize {
+			t.Errorf("expected sequence of %d values for target %d, actual %v", bingo.BoardSize, target, sequence)
+		}
+
+		ranking, _ := bingo.NewGame(sequence, boards).Play()
+
+		if len(ranking) != 1 || ranking[0].BoardIdx != target {
+			t.Errorf("sequence %v does not make board %d win first, ranking %+v", sequence, target, ranking)
+		}
+	}
+
+	// Already marked values shorten the sequence.
+	if _, err := boards[0].MarkValue(22); err != nil {
+		t.Fatalf("encountered error (%s) when marking the board", err.Error())
+	}
+
+	sequence, err := bingo.FindWinningSequence(boards, 0, exampleSequence)
+
+	if err != nil || len(sequence) != bingo.BoardSize-1 {
+		t.Errorf("expected sequence of %d values, actual %v (%v)", bingo.BoardSize-1, sequence, err)
+	}
+}
+
+func TestFindWinningSequenceImpossible(t *testing.T) {
+	// Identical boards always win together.
+	boards := []*bingo.Board{bingo.NewBoard(exampleBoardValues[0]), bingo.NewBoard(exampleBoardValues[0])}
+
+	_, err := bingo.FindWinningSequence(boards, 0, exampleSequence)
+
+	if !errors.Is(err, bingo.ErrNoWinningSequence) {
+		t.Errorf("expected ErrNoWinningSequence, actual %v", err)
+	}
+
+	// Pool lacks values of every line.
+	_, err = bingo.FindWinningSequence(exampleBoards(), 0, []int{22, 13, 17, 11})
+
+	if !errors.Is(err, bingo.ErrNoWinningSequence) {
+		t.Errorf("expected ErrNoWinningSequence, actual %v", err)
+	}
+}
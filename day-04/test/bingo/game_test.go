@@ -0,0 +1,108 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-4/internal/bingo"
+)
+
+var exampleSequence = []int{7, 4, 9, 5, 11, 17, 23, 2, 0, 14, 21, 24, 10, 16, 13, 6, 15, 25, 12, 22, 18, 20, 8, 19, 3, 26, 1}
+
+var exampleBoardValues = [][bingo.BoardSize][bingo.BoardSize]int{
+	{
+		{22, 13, 17, 11, 0},
+		{8, 2, 23, 4, 24},
+		{21, 9, 14, 16, 7},
+		{6, 10, 3, 18, 5},
+		{1, 12, 20, 15, 19},
+	},
+	{
+		{3, 15, 0, 2, 22},
+		{9, 18, 13, 17, 5},
+		{19, 8, 7, 25, 23},
+		{20, 11, 10, 24, 4},
+		{14, 21, 16, 12, 6},
+	},
+	{
+		{14, 21, 17, 24, 4},
+		{10, 16, 15, 9, 19},
+		{18, 8, 23, 26, 20},
+		{22, 11, 13, 6, 5},
+		{2, 0, 12, 3, 7},
+	},
+}
+
+func exampleBoards() []*bingo.Board {
+	var boards []*bingo.Board
+
+	for _, values := range exampleBoardValues {
+		boards = append(boards, bingo.NewBoard(values))
+	}
+
+	return boards
+}
+
+func TestGameRanking(t *testing.T) {
+	boards := exampleBoards()
+	game := bingo.NewGame(exampleSequence, boards)
+
+	var events []bingo.Event
+	game.OnDraw(func(e bingo.Event) {
+		events = append(events, e)
+	})
+
+	ranking, err := game.Play()
+
+	if err != nil {
+		t.Fatalf("encountered error (%s) while playing", err.Error())
+	}
+
+	if len(ranking) != 3 {
+		t.Fatalf("expected 3 boards in ranking, actual %d", len(ranking))
+	}
+
+	first, last := ranking[0], ranking[2]
+
+	if first.BoardIdx != 2 || first.DrawIdx != 11 || first.Value != 24 || first.Score != 4512 {
+		t.Errorf("unexpected first winner %+v", first)
+	}
+
+	if last.BoardIdx != 1 || last.Value != 13 || last.Score != 1924 {
+		t.Errorf("unexpected last winner %+v", last)
+	}
+
+	// Game stops drawing once every board won.
+	if len(events) != last.DrawIdx+1 || game.DrawCount() != last.DrawIdx+1 {
+		t.Errorf("expected %d draw events, actual %d", last.DrawIdx+1, len(events))
+	}
+
+	winnerCount := 0
+	for _, e := range events {
+		winnerCount += len(e.Winners)
+	}
+
+	if winnerCount != 3 {
+		t.Errorf("expected 3 winners across all events, actual %d", winnerCount)
+	}
+
+	// Boards passed to the game are left untouched.
+	for i, board := range boards {
+		if board.Won() {
+			t.Errorf("board %d was modified by the game", i)
+		}
+	}
+
+	// Replaying after reset yields the same ranking.
+	game.Reset()
+	replay, err := game.Play()
+
+	if err != nil {
+		t.Fatalf("encountered error (%s) while replaying", err.Error())
+	}
+
+	for i := range ranking {
+		if replay[i] != ranking[i] {
+			t.Errorf("replay ranking %+v differs from %+v", replay[i], ranking[i])
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-4/internal/bingo"
+)
+
+func TestGenerate(t *testing.T) {
+	cfg := bingo.GeneratorConfig{Cards: 40, PoolSize: 100, Size: bingo.BoardSize, Seed: 7, NoWinBefore: 12,
+		SingleWinnerAt: 15}
+
+	input, err := bingo.Generate(cfg)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s) while generating", err.Error())
+	}
+
+	var buf bytes.Buffer
+
+	if err := input.Write(&buf); err != nil {
+		t.Fatalf("encountered error (%s) while writing", err.Error())
+	}
+
+	sequence, boards, err := bingo.Parse(bytes.NewReader(buf.Bytes()))
+
+	if err != nil {
+		t.Fatalf("encountered error (%s) while parsing generated input", err.Error())
+	}
+
+	if len(sequence) != cfg.PoolSize || len(boards) != cfg.Cards {
+		t.Fatalf("expected %d values and %d boards, actual %d and %d", cfg.PoolSize, cfg.Cards, len(sequence),
+			len(boards))
+	}
+
+	unique := make(map[string]bool)
+	for _, board := range boards {
+		unique[board.String()] = true
+	}
+
+	if len(unique) != cfg.Cards {
+		t.Errorf("expected %d unique boards, actual %d", cfg.Cards, len(unique))
+	}
+
+	ranking, _ := bingo.NewGame(sequence, boards).Play()
+
+	if ranking[0].DrawIdx != cfg.SingleWinnerAt-1 || ranking[1].DrawIdx == ranking[0].DrawIdx {
+		t.Errorf("expected single winner on draw %d, ranking starts with %+v, %+v", cfg.SingleWinnerAt, ranking[0],
+			ranking[1])
+	}
+
+	// Same seed generates the same input.
+	again, _ := bingo.Generate(cfg)
+	var againBuf bytes.Buffer
+	again.Write(&againBuf)
+
+	if againBuf.String() != buf.String() {
+		t.Errorf("generator is not deterministic for a fixed seed")
+	}
+}
+
+func TestGenerateNoWinBefore(t *testing.T) {
+	cfg := bingo.GeneratorConfig{Cards: 20, PoolSize: 60, Size: bingo.BoardSize, Seed: 3, NoWinBefore: 30}
+	input, err := bingo.Generate(cfg)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s) while generating", err.Error())
+	}
+
+	var buf bytes.Buffer
+	input.Write(&buf)
+	sequence, boards, _ := bingo.Parse(&buf)
+	ranking, _ := bingo.NewGame(sequence, boards).Play()
+
+	if ranking[0].DrawIdx < cfg.NoWinBefore-1 {
+		t.Errorf("board won on draw %d, before %d", ranking[0].DrawIdx+1, cfg.NoWinBefore)
+	}
+
+	if _, err := bingo.Generate(bingo.GeneratorConfig{Cards: 1, PoolSize: 10, Size: bingo.BoardSize}); err == nil {
+		t.Errorf("expected error for too small pool, but none occurred")
+	}
+
+	if _, err := bingo.Generate(bingo.GeneratorConfig{Cards: 1, PoolSize: 100, Size: 0}); err == nil {
+		t.Errorf("expected error for empty cards, but none occurred")
+	}
+}
+
+func TestGenerateSingleWinnerWinsFirst(t *testing.T) {
+	for seed := int64(0); seed < 5; seed++ {
+		cfg := bingo.GeneratorConfig{Cards: 50, PoolSize: 100, Size: bingo.BoardSize, Seed: seed, SingleWinnerAt: 40}
+		input, err := bingo.Generate(cfg)
+
+		if err != nil {
+			t.Fatalf("encountered error (%s) while generating", err.Error())
+		}
+
+		var buf bytes.Buffer
+		input.Write(&buf)
+		sequence, boards, _ := bingo.Parse(&buf)
+		ranking, _ := bingo.NewGame(sequence, boards).Play()
+
+		if ranking[0].DrawIdx != cfg.SingleWinnerAt-1 || ranking[1].DrawIdx == ranking[0].DrawIdx {
+			t.Errorf("seed %d: expected single first win on draw %d, ranking starts with %+v, %+v", seed,
+				cfg.SingleWinnerAt, ranking[0], ranking[1])
+		}
+	}
+}
+
+func TestGenerateBoardSizes(t *testing.T) {
+	for _, size := range []int{1, 3, 4, 7} {
+		cfg := bingo.GeneratorConfig{Cards: 30, PoolSize: 200, Size: size, Seed: int64(size), SingleWinnerAt: 60}
+		input, err := bingo.Generate(cfg)
+
+		if err != nil {
+			t.Fatalf("size %d: encountered error (%s) while generating", size, err.Error())
+		}
+
+		var buf bytes.Buffer
+		input.Write(&buf)
+		sequence, boards, err := bingo.Parse(&buf)
+
+		if err != nil {
+			t.Fatalf("size %d: encountered error (%s) while parsing generated input", size, err.Error())
+		}
+
+		if len(boards) != cfg.Cards || boards[0].Size() != size {
+			t.Fatalf("size %d: expected %d boards of size %d, actual %d of size %d", size, cfg.Cards, size,
+				len(boards), boards[0].Size())
+		}
+
+		ranking, _ := bingo.NewGame(sequence, boards).Play()
+
+		if ranking[0].DrawIdx != cfg.SingleWinnerAt-1 || ranking[1].DrawIdx == ranking[0].DrawIdx {
+			t.Errorf("size %d: expected single first win on draw %d, ranking starts with %+v, %+v", size,
+				cfg.SingleWinnerAt, ranking[0], ranking[1])
+		}
+	}
+}
+
+func TestGenerateLargePool(t *testing.T) {
+	// Cards are sampled without shuffling the whole pool, so a huge pool stays cheap.
+	cfg := bingo.GeneratorConfig{Cards: 2000, PoolSize: 1000000, Size: bingo.BoardSize, Seed: 5}
+	input, err := bingo.Generate(cfg)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s) while generating", err.Error())
+	}
+
+	for _, card := range input.Cards {
+		seen := make(map[int]bool)
+
+		for _, row := range card {
+			for _, value := range row {
+				if value < 0 || value >= cfg.PoolSize || seen[value] {
+					t.Fatalf("card %v has value %d outside of pool or repeated", card, value)
+				}
+
+				seen[value] = true
+			}
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-4/internal/bingo"
+)
+
+// rowBoard creates a board whose first row holds the provided values and the rest holds values that are never drawn.
+func rowBoard(row [bingo.BoardSize]int) *bingo.Board {
+	var values [bingo.BoardSize][bingo.BoardSize]int
+
+	for i := 0; i < bingo.BoardSize; i++ {
+		for j := 0; j < bingo.BoardSize; j++ {
+			values[i][j] = 1000 + i*bingo.BoardSize + j
+		}
+	}
+
+	values[0] = row
+
+	return bingo.NewBoard(values)
+}
+
+// symmetricPool and symmetricBoards are symmetric under value mapping v -> 9-v, so both boards are equally likely to win.
+var symmetricPool = []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+func symmetricBoards() []*bingo.Board {
+	return []*bingo.Board{
+		rowBoard([bingo.BoardSize]int{1, 2, 3, 4, 5}),
+		rowBoard([bingo.BoardSize]int{4, 5, 6, 7, 8}),
+	}
+}
+
+func TestExactWinProbabilities(t *testing.T) {
+	probabilities, err := bingo.ExactWinProbabilities(symmetricBoards(), symmetricPool)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	if probabilities.Trials != 40320 {
+		t.Errorf("expected 8! draw orders, actual %d", probabilities.Trials)
+	}
+
+	for i, card := range probabilities.Cards {
+		if math.Abs(card.WinFirst.P-0.5) > 1e-12 {
+			t.Errorf("board %d should win first with probability 0.5, actual %f", i, card.WinFirst.P)
+		}
+
+		// Board wins on the 5th draw only if its five values are drawn first: 5!3!/8! = 1/56.
+		if math.Abs(card.DrawsToWin[5]-1.0/56) > 1e-12 {
+			t.Errorf("board %d should win on 5th draw with probability 1/56, actual %f", i, card.DrawsToWin[5])
+		}
+
+		if card.DrawsToWin[0] != 0 {
+			t.Errorf("board %d should always win, actual never-win probability %f", i, card.DrawsToWin[0])
+		}
+	}
+
+	_, err = bingo.ExactWinProbabilities(symmetricBoards(), exampleSequence)
+
+	if err == nil {
+		t.Errorf("expected error for too large pool, but none occurred")
+	}
+}
+
+func TestEstimateWinProbabilities(t *testing.T) {
+	cfg := bingo.MonteCarloConfig{Pool: symmetricPool, Trials: 20000, Seed: 42, Workers: 1}
+	single, err := bingo.EstimateWinProbabilities(symmetricBoards(), cfg)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	for i, card := range single.Cards {
+		if card.WinFirst.Low > 0.5 || card.WinFirst.High < 0.5 {
+			t.Errorf("confidence interval [%f, %f] of board %d does not contain 0.5", card.WinFirst.Low,
+				card.WinFirst.High, i)
+		}
+	}
+
+	// Results depend only on the seed and not on the number of workers.
+	cfg.Workers = 4
+	parallel, err := bingo.EstimateWinProbabilities(symmetricBoards(), cfg)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	for i := range single.Cards {
+		if single.Cards[i].WinFirst != parallel.Cards[i].WinFirst {
+			t.Errorf("board %d estimate differs between 1 and 4 workers", i)
+		}
+	}
+}
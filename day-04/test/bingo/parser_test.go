@@ -0,0 +1,145 @@
+package test
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-4/internal/bingo"
+)
+
+const exampleFile = `7,4,9,5,11,17,23,2,0,14,21,24,10,16,13,6,15,25,12,22,18,20,8,19,3,26,1
+
+22 13 17 11  0
+ 8  2 23  4 24
+21  9 14 16  7
+ 6 10  3 18  5
+ 1 12 20 15 19
+
+ 3 15  0  2 22
+ 9 18 13 17  5
+19  8  7 25 23
+20 11 10 24  4
+14 21 16 12  6
+
+
+	14 21 17 24  4
+10	16 15  9 19
+18  8 23 26 20
+22 11 13  6  5
+ 2  0 12  3  7`
+
+func TestParse(t *testing.T) {
+	sequence, boards, err := bingo.Parse(strings.NewReader(exampleFile))
+
+	if err != nil {
+		t.Fatalf("encountered error (%s) while parsing", err.Error())
+	}
+
+	if len(sequence) != len(exampleSequence) {
+		t.Errorf("expected sequence of %d values, actual %d", len(exampleSequence), len(sequence))
+	}
+
+	// Last board is not followed by a blank line, but must still be parsed.
+	if len(boards) != 3 {
+		t.Fatalf("expected 3 boards, actual %d", len(boards))
+	}
+
+	for i, board := range boards {
+		if board.String() != bingo.NewBoard(exampleBoardValues[i]).String() {
+			t.Errorf("board %d parsed incorrectly:\n%s", i, board.String())
+		}
+	}
+}
+
+func TestParseLongSequence(t *testing.T) {
+	// Sequence of a large pool does not fit into the default 64KB buffer of bufio.Scanner.
+	values := make([]string, 20000)
+	for i := range values {
+		values[i] = strconv.Itoa(i)
+	}
+
+	input := strings.Join(values, ",") + "\n\n" + exampleFile[strings.Index(exampleFile, "\n")+2:]
+
+	if len(input) <= 64*1024 {
+		t.Fatalf("expected input longer than 64KB, actual %d bytes", len(input))
+	}
+
+	sequence, boards, err := bingo.Parse(strings.NewReader(input))
+
+	if err != nil {
+		t.Fatalf("encountered error (%s) while parsing", err.Error())
+	}
+
+	if len(sequence) != len(values) || sequence[len(values)-1] != len(values)-1 || len(boards) != 3 {
+		t.Errorf("expected %d values and 3 boards, actual %d values and %d boards", len(values), len(sequence),
+			len(boards))
+	}
+
+	sequence, err = bingo.ParseSequence(strings.NewReader(strings.Join(values, ",")))
+
+	if err != nil || len(sequence) != len(values) {
+		t.Errorf("expected sequence of %d values, actual %d (%v)", len(values), len(sequence), err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	testCases := map[string]string{
+		"":                                "no data",
+		"1,x,3\n":                         "line 1:",
+		"1,2\n\n1 2 3 4 5\n1 2 3 4\n":     "line 4:",
+		"1,2\n\n1 2 3 4 5\n1 2 3 4 5\n\n": "line 3: incomplete",
+		"1,2\n\n1 2 3 4 5\n1 2 3 4 5\n1 2 3 4 5\n1 2 3 4 5\n1 2 3 4 5\n1 2 3 4 5\n": "line 8:",
+		"1,2\n\n1 2\n3 4\n5 6\n":             "line 5: bingo board starting on line 3",
+		"1,2\n\n1 2 3\n4 5 6\n\n7 8\n9 10\n": "line 3: incomplete",
+	}
+
+	for input, expectedError := range testCases {
+		_, _, err := bingo.Parse(strings.NewReader(input))
+
+		if err == nil || !strings.Contains(err.Error(), expectedError) {
+			t.Errorf("expected error containing '%s' for input %q, actual %v", expectedError, input, err)
+		}
+	}
+}
+
+func TestParseBoardSizes(t *testing.T) {
+	sequence, boards, err := bingo.Parse(strings.NewReader("3,1,4,2\n\n1 2\n3 4\n\n7\n\n5 6 7\n8 9 10\n11 12 13"))
+
+	if err != nil {
+		t.Fatalf("encountered error (%s) while parsing", err.Error())
+	}
+
+	if len(boards) != 3 || boards[0].Size() != 2 || boards[1].Size() != 1 || boards[2].Size() != 3 {
+		t.Fatalf("expected boards of sizes 2, 1 and 3, actual %v", boards)
+	}
+
+	ranking, _ := bingo.NewGame(sequence, boards).Play()
+
+	// Board of size 2 wins once its first column 1, 3 is drawn.
+	if len(ranking) != 1 || ranking[0].BoardIdx != 0 || ranking[0].DrawIdx != 1 || ranking[0].Score != 1*(2+4) {
+		t.Errorf("expected board 1 to win on draw 2 with score 6, actual %+v", ranking)
+	}
+}
+
+func TestParseSequenceAndBoards(t *testing.T) {
+	sequence, err := bingo.ParseSequence(strings.NewReader(" 1, 2 ,3,\n4,5\n"))
+
+	if err != nil {
+		t.Fatalf("encountered error (%s) while parsing sequence", err.Error())
+	}
+
+	if len(sequence) != 5 || sequence[4] != 5 {
+		t.Errorf("unexpected sequence %v", sequence)
+	}
+
+	boards, err := bingo.ParseBoards(strings.NewReader(exampleFile[strings.Index(exampleFile, "\n"):]))
+
+	if err != nil {
+		t.Fatalf("encountered error (%s) while parsing boards", err.Error())
+	}
+
+	if len(boards) != 3 {
+		t.Errorf("expected 3 boards, actual %d", len(boards))
+	}
+}
@@ -0,0 +1,239 @@
+package test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-4/internal/bingo"
+)
+
+func TestBoardString(t *testing.T) {
+	board := bingo.NewBoard(exampleBoardValues[2])
+	board.MarkValue(14)
+	board.MarkValue(3)
+
+	expected := "[14]  21   17   24    4 \n" +
+		" 10   16   15    9   19 \n" +
+		" 18    8   23   26   20 \n" +
+		" 22   11   13    6    5 \n" +
+		"  2    0   12  [ 3]   7 \n"
+
+	if board.String() != expected {
+		t.Errorf("unexpected board rendering:\n%s", board.String())
+	}
+}
+
+func TestBoardJSON(t *testing.T) {
+	board := bingo.NewBoard(exampleBoardValues[2])
+
+	for _, value := range exampleSequence[:12] {
+		board.MarkValue(value)
+	}
+
+	data, err := json.Marshal(board)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s) while marshaling", err.Error())
+	}
+
+	var decoded bingo.Board
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("encountered error (%s) while unmarshaling", err.Error())
+	}
+
+	if !decoded.Won() || decoded.Score() != 4512 || decoded.String() != board.String() {
+		t.Errorf("decoded board differs from the original:\n%s", decoded.String())
+	}
+
+	// Won state not backed by marks is rejected.
+	inconsistent := strings.Replace(string(data), `"won":true`, `"won":false`, 1)
+
+	if err := json.Unmarshal([]byte(inconsistent), &decoded); err == nil {
+		t.Errorf("expected error for inconsistent board, but none occurred")
+	}
+
+	// Score that does not follow from the marks and the winning value is rejected.
+	replacements := [][2]string{{`"score":4512`, `"score":4513`}, {`"winning_value":24`, `"winning_value":7`}}
+
+	for _, replacement := range replacements {
+		if !strings.Contains(string(data), replacement[0]) {
+			t.Fatalf("expected %s in %s", replacement[0], data)
+		}
+
+		tampered := strings.Replace(string(data), replacement[0], replacement[1], 1)
+
+		if err := json.Unmarshal([]byte(tampered), &decoded); err == nil {
+			t.Errorf("expected error for board with %s, but none occurred", replacement[1])
+		}
+	}
+}
+
+func TestBoardJSONSizes(t *testing.T) {
+	board, err := bingo.NewSquareBoard([][]int{{1, 2, 3}, {4, 5, 6}, {7, 8, 9}})
+
+	if err != nil {
+		t.Fatalf("encountered error (%s) while creating board", err.Error())
+	}
+
+	board.MarkValue(2)
+	board.MarkValue(5)
+	board.MarkValue(8)
+
+	data, _ := json.Marshal(board)
+	var decoded bingo.Board
+
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("encountered error (%s) while unmarshaling", err.Error())
+	}
+
+	if decoded.Size() != 3 || decoded.Score() != 8*(1+3+4+6+7+9) || decoded.String() != board.String() {
+		t.Errorf("decoded board differs from the original:\n%s", decoded.String())
+	}
+
+	testCases := []string{
+		`{"values":[[1,2],[3]],"marked":[[false,false],[false,false]]}`,
+		`{"values":[[1,2],[3,4]],"marked":[[false,false]]}`,
+		`{"values":[[1,2],[3,4]],"marked":[[false,false],[false]]}`,
+		`{"values":[],"marked":[]}`,
+	}
+
+	for _, input := range testCases {
+		if err := json.Unmarshal([]byte(input), &decoded); err == nil {
+			t.Errorf("expected error for board %s, but none occurred", input)
+		}
+	}
+
+	if _, err := bingo.NewSquareBoard([][]int{{1, 2, 3}, {4, 5, 6}}); err == nil {
+		t.Errorf("expected error for board that is not square, but none occurred")
+	}
+}
+
+func TestBoardStringWideValues(t *testing.T) {
+	values := exampleBoardValues[2]
+	values[0][1] = 123
+	values[1][0] = -45
+	board := bingo.NewBoard(values)
+	board.MarkValue(123)
+
+	lines := strings.Split(strings.TrimRight(board.String(), "\n"), "\n")
+
+	for _, line := range lines {
+		if len(line) != len(lines[0]) {
+			t.Fatalf("rows are misaligned:\n%s", board.String())
+		}
+	}
+
+	if !strings.HasPrefix(lines[0], "  14  [123]") || !strings.HasPrefix(lines[1], " -45 ") {
+		t.Errorf("unexpected board rendering:\n%s", board.String())
+	}
+}
+
+func TestGameSnapshot(t *testing.T) {
+	game := bingo.NewGame(exampleSequence, exampleBoards())
+
+	for i := 0; i < 14; i++ {
+		if _, err := game.Draw(); err != nil {
+			t.Fatalf("encountered error (%s) while drawing", err.Error())
+		}
+	}
+
+	data, err := json.Marshal(game.Snapshot())
+
+	if err != nil {
+		t.Fatalf("encountered error (%s) while marshaling snapshot", err.Error())
+	}
+
+	var snapshot bingo.GameSnapshot
+
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("encountered error (%s) while unmarshaling snapshot", err.Error())
+	}
+
+	restored, err := bingo.RestoreGame(snapshot)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s) while restoring game", err.Error())
+	}
+
+	expected, _ := game.Play()
+	actual, err := restored.Play()
+
+	if err != nil {
+		t.Fatalf("encountered error (%s) while playing restored game", err.Error())
+	}
+
+	if len(actual) != len(expected) {
+		t.Fatalf("restored game ranking has %d entries, expected %d", len(actual), len(expected))
+	}
+
+	for i := range expected {
+		if actual[i] != expected[i] {
+			t.Errorf("restored ranking %+v differs from %+v", actual[i], expected[i])
+		}
+	}
+
+	snapshot.DrawCount = len(snapshot.Sequence) + 1
+
+	if _, err := bingo.RestoreGame(snapshot); err == nil {
+		t.Errorf("expected error for invalid draw count, but none occurred")
+	}
+}
+
+// snapshotAfter returns a deep copy of the snapshot of the example game after the given number of draws.
+func snapshotAfter(t *testing.T, draws int) bingo.GameSnapshot {
+	game := bingo.NewGame(exampleSequence, exampleBoards())
+
+	for i := 0; i < draws; i++ {
+		game.Draw()
+	}
+
+	data, _ := json.Marshal(game.Snapshot())
+	var snapshot bingo.GameSnapshot
+
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		t.Fatalf("encountered error (%s) while unmarshaling snapshot", err.Error())
+	}
+
+	return snapshot
+}
+
+func TestRestoreGameInconsistent(t *testing.T) {
+	if snapshot := snapshotAfter(t, len(exampleSequence)); len(snapshot.Ranking) != 3 {
+		t.Fatalf("expected every board to win, actual ranking %+v", snapshot.Ranking)
+	}
+
+	testCases := map[string]struct {
+		draws  int
+		tamper func(s *bingo.GameSnapshot)
+	}{
+		"not drawn on draw": {len(exampleSequence), func(s *bingo.GameSnapshot) {
+			s.Ranking[1].Value = s.Sequence[s.Ranking[1].DrawIdx+1]
+		}},
+		"not ordered by draw": {len(exampleSequence), func(s *bingo.GameSnapshot) {
+			s.Ranking[0], s.Ranking[1] = s.Ranking[1], s.Ranking[0]
+		}},
+		"marks of board 2 do not match": {5, func(s *bingo.GameSnapshot) {
+			// 23 is on board 2, but it is not drawn in the first 5 draws.
+			s.Boards[1].MarkValue(23)
+		}},
+		"marks of board 1 do not match": {5, func(s *bingo.GameSnapshot) {
+			s.Sequence[0] = 99
+		}},
+	}
+
+	for expected, testCase := range testCases {
+		snapshot := snapshotAfter(t, testCase.draws)
+
+		if _, err := bingo.RestoreGame(snapshot); err != nil {
+			t.Fatalf("%s: encountered error (%s) before tampering", expected, err.Error())
+		}
+
+		testCase.tamper(&snapshot)
+
+		if _, err := bingo.RestoreGame(snapshot); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("expected error containing '%s', actual %v", expected, err)
+		}
+	}
+}
@@ -1,23 +1,27 @@
 package main
 
 import (
-	"bufio"
+	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
+	"image/color"
 	"log"
 	"os"
-	"regexp"
+	"path/filepath"
+	"strconv"
+	"strings"
 
+	"github.com/PrimozLavric/advent-of-code-2021/day-5/internal/heatmap"
 	"github.com/PrimozLavric/advent-of-code-2021/day-5/internal/line"
-	"github.com/PrimozLavric/advent-of-code-2021/day-5/internal/util"
 )
 
 type application struct {
 	log *log.Logger
 }
 
-func (app *application) parseLinesFile(filePath string) ([]*line.Line, error) {
+// parseLinesFile reads lines from the file in the provided format.
+func (app *application) parseLinesFile(filePath string, format line.Format) ([]*line.Line, error) {
 	file, err := os.Open(filePath)
 
 	if err != nil {
@@ -33,66 +37,306 @@ func (app *application) parseLinesFile(filePath string) ([]*line.Line, error) {
 		}
 	}()
 
-	fileScanner := bufio.NewScanner(file)
-	fileScanner.Split(bufio.ScanLines)
+	return line.ReadLines(file, format)
+}
+
+// writeLinesFile writes lines to the file in the provided format.
+func (app *application) writeLinesFile(filePath string, lines []*line.Line, format line.Format) error {
+	file, err := os.Create(filePath)
+
+	if err != nil {
+		return err
+	}
+
+	// Defer close the file.
+	defer func() {
+		err = file.Close()
+
+		if err != nil {
+			app.log.Printf("Failed to close file: %s\n", filePath)
+		}
+	}()
+
+	return line.WriteLines(file, lines, format)
+}
+
+// resolveFormat returns the named format or detects it from the file path if name is "auto".
+func resolveFormat(name string, filePath string) (line.Format, error) {
+	if name == "auto" {
+		return line.DetectFormat(filePath), nil
+	}
+
+	return line.ParseFormat(name)
+}
+
+// writeHeatmap renders coverage of all lines to a PNG, PPM or SVG file (chosen by the file extension). SVG output
+// additionally shows the lines on top of the coverage.
+func (app *application) writeHeatmap(filePath string, lines []*line.Line, rasterizer line.Rasterizer, ramp heatmap.Ramp,
+	scale int) error {
+	low, high := line.FindBounds(lines)
+	cells, ok := line.BoxCells(low, high, line.DenseGridMaxCells)
+
+	if !ok {
+		return errors.New(fmt.Sprintf("bounding box [%v, %v] is too large for a heatmap", low, high))
+	}
+
+	ext := strings.ToLower(filepath.Ext(filePath))
+
+	// Raster images take scale x scale pixels per cell, SVG cells are scaled by the viewer.
+	if (ext == ".png" || ext == ".ppm") && uint64(scale) > heatmap.MaxPixels/cells/uint64(scale) {
+		return errors.New(fmt.Sprintf("heatmap of %d cells at scale %d exceeds %d pixels", cells, scale,
+			heatmap.MaxPixels))
+	}
+
+	grid := line.MakeOffsetGrid(low, high)
+
+	for _, l := range lines {
+		grid.ApplyLineWith(l, rasterizer)
+	}
 
-	lineRegex := regexp.MustCompile("([0-9]+),([0-9]+) -> ([0-9]+),([0-9]+)")
+	file, err := os.Create(filePath)
 
-	var lines []*line.Line
+	if err != nil {
+		return err
+	}
 
-	rowIdx := 0
-	for fileScanner.Scan() {
-		matches := lineRegex.FindStringSubmatch(fileScanner.Text())
+	// Defer close the file.
+	defer func() {
+		err = file.Close()
 
-		if matches == nil || len(matches) != 5 {
-			return nil, errors.New(fmt.Sprintf("failed to parse line %d (%s)", rowIdx, fileScanner.Text()))
+		if err != nil {
+			app.log.Printf("Failed to close file: %s\n", filePath)
 		}
+	}()
+
+	switch ext {
+	case ".png":
+		return heatmap.WritePNG(file, heatmap.Image(grid.Cells, ramp, scale))
+	case ".ppm":
+		return heatmap.WritePPM(file, heatmap.Image(grid.Cells, ramp, scale))
+	case ".svg":
+		lineColor := color.RGBA{R: 0x00, G: 0xc0, B: 0xff, A: 0xff}
+		return heatmap.WriteSVG(file, grid.Cells, grid.Origin, lines, ramp, scale, lineColor)
+	}
+
+	return errors.New(fmt.Sprintf("unknown heatmap extension '%s' (expected .png, .ppm or .svg)", filepath.Ext(filePath)))
+}
+
+// overlapReport is the JSON output of overlap queries. Only the requested queries are present, a requested query
+// without results is written as an empty list.
+type overlapReport struct {
+	CoveredAtLeast *[]line.CellCoverage `json:"covered_at_least,omitempty"`
+	HotPoints      *[]line.CellCoverage `json:"hot_points,omitempty"`
+	Cell           *line.CellCoverage   `json:"cell,omitempty"`
+	LineOverlaps   *[]line.LineOverlap  `json:"line_overlaps,omitempty"`
+}
+
+// cellCoverageList returns pointer to the cells, replacing nil with an empty list.
+func cellCoverageList(cells []line.CellCoverage) *[]line.CellCoverage {
+	if cells == nil {
+		cells = []line.CellCoverage{}
+	}
+
+	return &cells
+}
+
+// parsePoint parses a point in "x,y" format.
+func parsePoint(s string) (line.Point, error) {
+	parts := strings.Split(s, ",")
+
+	if len(parts) != 2 {
+		return line.Point{}, errors.New(fmt.Sprintf("point '%s' is not in x,y format", s))
+	}
+
+	x, errX := strconv.Atoi(strings.TrimSpace(parts[0]))
+	y, errY := strconv.Atoi(strings.TrimSpace(parts[1]))
+
+	if errX != nil || errY != nil {
+		return line.Point{}, errors.New(fmt.Sprintf("point '%s' is not in x,y format", s))
+	}
+
+	return line.Point{X: x, Y: y}, nil
+}
+
+// queryOverlaps prints the requested overlap queries as JSON.
+func (app *application) queryOverlaps(lines []*line.Line, rasterizer line.Rasterizer, minCoverage int, hot int,
+	cell string, lineOverlaps bool) {
+	index := line.NewOverlapIndex(lines, rasterizer)
+	report := overlapReport{}
+
+	if minCoverage > 0 {
+		report.CoveredAtLeast = cellCoverageList(index.CoveredAtLeast(minCoverage))
+	}
+
+	if hot > 0 {
+		report.HotPoints = cellCoverageList(index.HotPoints(hot))
+	}
 
-		intEntries, err := util.SliceAtoi(matches[1:])
+	if cell != "" {
+		p, err := parsePoint(cell)
 
 		if err != nil {
-			return nil, errors.New(fmt.Sprintf("failed to parse line %d (%s)", rowIdx, fileScanner.Text()))
+			app.log.Fatalf("Encountered error while parsing queried cell (%s).", err.Error())
+		}
+
+		report.Cell = &line.CellCoverage{X: p.X, Y: p.Y, Coverage: index.Coverage(p), Lines: index.LinesAt(p)}
+	}
+
+	if lineOverlaps {
+		overlaps := index.LineOverlaps()
+
+		if overlaps == nil {
+			overlaps = []line.LineOverlap{}
+		}
+
+		report.LineOverlaps = &overlaps
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(report); err != nil {
+		app.log.Fatalf("Encountered error while writing overlap report (%s).", err.Error())
+	}
+}
+
+// applyLines applies lines to the grid. Dense grids are filled by the provided number of workers.
+func applyLines(grid line.CoverageGrid, lines []*line.Line, rasterizer line.Rasterizer, workers int) {
+	if dense, ok := grid.(*line.OffsetGrid); ok && workers != 1 {
+		dense.ApplyLinesParallel(lines, rasterizer, workers)
+		return
+	}
+
+	for _, l := range lines {
+		grid.ApplyLineWith(l, rasterizer)
+	}
+}
+
+// countIntersectionsWithSweep prints lattice intersection counts computed with line.FindIntersections.
+func (app *application) countIntersectionsWithSweep(lines []*line.Line) {
+	var linesHV []*line.Line
+
+	for _, l := range lines {
+		if l.IsHorizontal() || l.IsVertical() {
+			linesHV = append(linesHV, l)
 		}
+	}
+
+	resHV, err := line.FindIntersections(linesHV)
 
-		lines = append(lines, line.NewLine(intEntries[0], intEntries[1], intEntries[2], intEntries[3]))
+	if err != nil {
+		app.log.Fatalf("Encountered error while intersecting lines (%s).", err.Error())
+	}
 
-		rowIdx++
+	fmt.Printf("Number of horizontal and vertical lines intersections: %d\n", resHV.LatticeOverlapCount)
+
+	resALL, err := line.FindIntersections(lines)
+
+	if err != nil {
+		app.log.Fatalf("Encountered error while intersecting lines (%s).", err.Error())
 	}
 
-	return lines, nil
+	fmt.Printf("Number of all lines intersections: %d\n", resALL.LatticeOverlapCount)
 }
 
 func main() {
 	var linesFile = flag.String("file", "input.txt", "Hydrothermal vents lines file.")
+	var formatName = flag.String("format", "auto", "Lines file format (auto, text, csv or geojson).")
+	var outFile = flag.String("out", "", "Write parsed lines to this file (empty disables writing).")
+	var outFormatName = flag.String("out-format", "auto", "Output lines file format (auto, text, csv or geojson).")
+	var rasterizerName = flag.String("rasterizer", "bresenham", "Line rasterizer (bresenham or supercover).")
+	var sweep = flag.Bool("sweep", false, "Count intersections analytically with a sweep line instead of painting a grid.")
+	var heatmapFile = flag.String("heatmap", "", "Write coverage heatmap to this .png, .ppm or .svg file (empty disables).")
+	var rampColors = flag.String("ramp", "", "Heatmap color ramp as comma separated hex colors (e.g. #000000,#ff0000).")
+	var heatmapScale = flag.Int("scale", 1, "Heatmap pixels (or SVG units) per grid cell.")
+	var minCoverage = flag.Int("min-coverage", 0, "Report cells covered by at least this many lines as JSON (0 disables).")
+	var hotPoints = flag.Int("hot", 0, "Report this many most covered cells with their lines as JSON (0 disables).")
+	var cell = flag.String("cell", "", "Report lines covering the cell at x,y as JSON (empty disables).")
+	var lineOverlaps = flag.Bool("line-overlaps", false, "Report overlapping cells of every line as JSON.")
+	var workers = flag.Int("workers", 1, "Number of workers rasterizing lines into a dense grid (0 uses all CPUs).")
 	flag.Parse()
 
 	app := application{log: log.Default()}
 
-	lines, err := app.parseLinesFile(*linesFile)
+	rasterizers := map[string]line.Rasterizer{"bresenham": line.Bresenham, "supercover": line.Supercover}
+	rasterizer, ok := rasterizers[*rasterizerName]
+
+	if !ok {
+		app.log.Fatalf("Unknown rasterizer %s.", *rasterizerName)
+	}
+
+	format, err := resolveFormat(*formatName, *linesFile)
+
+	if err != nil {
+		app.log.Fatalf("Encountered error while resolving input format (%s).", err.Error())
+	}
+
+	lines, err := app.parseLinesFile(*linesFile, format)
 
 	if err != nil {
 		app.log.Fatalf("Encountered error during hydrothermal vent lines file parsing (%s).", err.Error())
 	}
 
-	maxX, maxY := line.FindMaxXY(lines)
+	if *outFile != "" {
+		outFormat, err := resolveFormat(*outFormatName, *outFile)
+
+		if err != nil {
+			app.log.Fatalf("Encountered error while resolving output format (%s).", err.Error())
+		}
+
+		if err := app.writeLinesFile(*outFile, lines, outFormat); err != nil {
+			app.log.Fatalf("Encountered error while writing hydrothermal vent lines file (%s).", err.Error())
+		}
+	}
+
+	if *heatmapFile != "" {
+		ramp := heatmap.DefaultRamp
+
+		if *rampColors != "" {
+			if ramp, err = heatmap.ParseRamp(*rampColors); err != nil {
+				app.log.Fatalf("Encountered error while parsing color ramp (%s).", err.Error())
+			}
+		}
+
+		if *heatmapScale < 1 {
+			app.log.Fatalf("Heatmap scale must be positive.")
+		}
+
+		if err := app.writeHeatmap(*heatmapFile, lines, rasterizer, ramp, *heatmapScale); err != nil {
+			app.log.Fatalf("Encountered error while writing heatmap (%s).", err.Error())
+		}
+	}
+
+	if *minCoverage > 0 || *hotPoints > 0 || *cell != "" || *lineOverlaps {
+		app.queryOverlaps(lines, rasterizer, *minCoverage, *hotPoints, *cell, *lineOverlaps)
+		return
+	}
 
-	gridHV := line.MakeGrid(maxX+1, maxY+1)
+	if *sweep {
+		app.countIntersectionsWithSweep(lines)
+		return
+	}
+
+	low, high := line.FindBounds(lines)
+
+	var linesHV []*line.Line
 
-	// Compute number of horizontal and vertical lines intersections.
 	for _, l := range lines {
 		if l.IsHorizontal() || l.IsVertical() {
-			gridHV.ApplyLine(l)
+			linesHV = append(linesHV, l)
 		}
 	}
 
-	fmt.Printf("Number of horizontal and vertical lines intersections: %d\n", gridHV.CountIntersections())
+	// Compute number of horizontal and vertical lines intersections. Dense grid is used for small bounding boxes and
+	// sparse grid for huge ones.
+	gridHV := line.MakeCoverageGrid(low, high)
+	applyLines(gridHV, linesHV, rasterizer, *workers)
 
-	gridALL := line.MakeGrid(maxX+1, maxY+1)
+	fmt.Printf("Number of horizontal and vertical lines intersections: %d\n", gridHV.CountIntersections())
 
 	// Compute number of all intersections.
-	for _, l := range lines {
-		gridALL.ApplyLine(l)
-	}
+	gridALL := line.MakeCoverageGrid(low, high)
+	applyLines(gridALL, lines, rasterizer, *workers)
 
 	fmt.Printf("Number of all lines intersections: %d\n", gridALL.CountIntersections())
 }
@@ -0,0 +1,203 @@
+package heatmap
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-5/internal/line"
+)
+
+// MaxPixels is the largest number of pixels of a rendered Image. It bounds image memory to 4 bytes per pixel.
+const MaxPixels = 1 << 26
+
+// Ramp is a list of color stops evenly spread from zero coverage to the maximal coverage of the grid.
+type Ramp []color.RGBA
+
+// DefaultRamp goes from black through red to yellow and white.
+var DefaultRamp = Ramp{
+	{R: 0x00, G: 0x00, B: 0x00, A: 0xff},
+	{R: 0xc0, G: 0x00, B: 0x00, A: 0xff},
+	{R: 0xff, G: 0xd0, B: 0x00, A: 0xff},
+	{R: 0xff, G: 0xff, B: 0xff, A: 0xff},
+}
+
+// ParseRamp parses comma separated hex colors (e.g. "#000000,#ff0000,#ffff00").
+func ParseRamp(s string) (Ramp, error) {
+	var ramp Ramp
+
+	for i, stop := range strings.Split(s, ",") {
+		hex := strings.TrimPrefix(strings.TrimSpace(stop), "#")
+
+		if len(hex) != 6 {
+			return nil, errors.New(fmt.Sprintf("color stop %d (%s) is not a 6 digit hex color", i+1, stop))
+		}
+
+		value, err := strconv.ParseUint(hex, 16, 32)
+
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("color stop %d (%s) is not a 6 digit hex color", i+1, stop))
+		}
+
+		ramp = append(ramp, color.RGBA{R: uint8(value >> 16), G: uint8(value >> 8), B: uint8(value), A: 0xff})
+	}
+
+	if len(ramp) < 2 {
+		return nil, errors.New("color ramp needs at least 2 stops")
+	}
+
+	return ramp, nil
+}
+
+// At returns the ramp color for coverage value in range [0, maxValue].
+func (ramp Ramp) At(value int, maxValue int) color.RGBA {
+	if value <= 0 || maxValue <= 0 {
+		return ramp[0]
+	}
+
+	if value >= maxValue {
+		return ramp[len(ramp)-1]
+	}
+
+	// Position between the stops.
+	pos := float64(value) / float64(maxValue) * float64(len(ramp)-1)
+	idx := int(pos)
+	t := pos - float64(idx)
+	a, b := ramp[idx], ramp[idx+1]
+
+	lerp := func(x, y uint8) uint8 {
+		return uint8(float64(x) + (float64(y)-float64(x))*t + 0.5)
+	}
+
+	return color.RGBA{R: lerp(a.R, b.R), G: lerp(a.G, b.G), B: lerp(a.B, b.B), A: 0xff}
+}
+
+// maxCoverage finds the largest value in the grid.
+func maxCoverage(grid line.Grid) int {
+	maxValue := 0
+
+	for _, column := range grid {
+		for _, value := range column {
+			if value > maxValue {
+				maxValue = value
+			}
+		}
+	}
+
+	return maxValue
+}
+
+// Image renders the grid with every cell drawn as a scale x scale square. Grid x axis maps to image columns and y
+// axis to image rows.
+func Image(grid line.Grid, ramp Ramp, scale int) *image.RGBA {
+	width := len(grid)
+	height := 0
+
+	if width > 0 {
+		height = len(grid[0])
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, width*scale, height*scale))
+	maxValue := maxCoverage(grid)
+
+	for x, column := range grid {
+		for y, value := range column {
+			c := ramp.At(value, maxValue)
+
+			for dx := 0; dx < scale; dx++ {
+				for dy := 0; dy < scale; dy++ {
+					img.SetRGBA(x*scale+dx, y*scale+dy, c)
+				}
+			}
+		}
+	}
+
+	return img
+}
+
+// WritePNG encodes the image as PNG.
+func WritePNG(w io.Writer, img image.Image) error {
+	return png.Encode(w, img)
+}
+
+// WritePPM encodes the image as binary PPM (P6).
+func WritePPM(w io.Writer, img image.Image) error {
+	bw := bufio.NewWriter(w)
+	bounds := img.Bounds()
+
+	if _, err := fmt.Fprintf(bw, "P6\n%d %d\n255\n", bounds.Dx(), bounds.Dy()); err != nil {
+		return err
+	}
+
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := color.RGBAModel.Convert(img.At(x, y)).(color.RGBA)
+
+			if _, err := bw.Write([]byte{c.R, c.G, c.B}); err != nil {
+				return err
+			}
+		}
+	}
+
+	return bw.Flush()
+}
+
+// hexColor formats color as #rrggbb.
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// WriteSVG renders the grid as SVG with cells of size cellSize and the lines drawn on top of it. Origin is the point
+// that corresponds to grid cell [0][0], so lines are drawn through the centers of the cells they cover.
+func WriteSVG(w io.Writer, grid line.Grid, origin line.Point, lines []*line.Line, ramp Ramp, cellSize int,
+	lineColor color.RGBA) error {
+	bw := bufio.NewWriter(w)
+	width := len(grid)
+	height := 0
+
+	if width > 0 {
+		height = len(grid[0])
+	}
+
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		width*cellSize, height*cellSize, width*cellSize, height*cellSize)
+	fmt.Fprintf(bw, `<rect width="100%%" height="100%%" fill="%s"/>`+"\n", hexColor(ramp[0]))
+
+	maxValue := maxCoverage(grid)
+	bw.WriteString(`<g shape-rendering="crispEdges">` + "\n")
+
+	for x, column := range grid {
+		for y, value := range column {
+			if value == 0 {
+				continue
+			}
+
+			fmt.Fprintf(bw, `<rect x="%d" y="%d" width="%d" height="%d" fill="%s"><title>%d,%d: %d</title></rect>`+"\n",
+				x*cellSize, y*cellSize, cellSize, cellSize, hexColor(ramp.At(value, maxValue)), x+origin.X, y+origin.Y,
+				value)
+		}
+	}
+
+	bw.WriteString("</g>\n")
+	fmt.Fprintf(bw, `<g stroke="%s" stroke-width="%g" stroke-linecap="round" opacity="0.8">`+"\n", hexColor(lineColor),
+		float64(cellSize)/4)
+
+	center := func(c int, o int) float64 {
+		return (float64(c-o) + 0.5) * float64(cellSize)
+	}
+
+	for i, l := range lines {
+		fmt.Fprintf(bw, `<line x1="%g" y1="%g" x2="%g" y2="%g"><title>line %d</title></line>`+"\n",
+			center(l.A.X, origin.X), center(l.A.Y, origin.Y), center(l.B.X, origin.X), center(l.B.Y, origin.Y), i)
+	}
+
+	bw.WriteString("</g>\n</svg>\n")
+
+	return bw.Flush()
+}
@@ -0,0 +1,336 @@
+package line
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Format of a lines file.
+type Format int
+
+const (
+	// FormatText one "x1,y1 -> x2,y2" line per row.
+	FormatText Format = iota
+	// FormatCSV one "x1,y1,x2,y2" record per row, optionally preceded by a header row.
+	FormatCSV
+	// FormatGeoJSON GeoJSON with LineString or MultiLineString geometries.
+	FormatGeoJSON
+)
+
+// ParseFormat converts a format name (text, csv or geojson) to Format.
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "text", "txt":
+		return FormatText, nil
+	case "csv":
+		return FormatCSV, nil
+	case "geojson", "json":
+		return FormatGeoJSON, nil
+	}
+
+	return FormatText, errors.New(fmt.Sprintf("unknown lines format '%s'", name))
+}
+
+// DetectFormat deduces the format from the file extension. Unknown extensions are treated as text.
+func DetectFormat(filePath string) Format {
+	format, err := ParseFormat(strings.TrimPrefix(filepath.Ext(filePath), "."))
+
+	if err != nil {
+		return FormatText
+	}
+
+	return format
+}
+
+// ReadLines reads lines in the given format.
+func ReadLines(r io.Reader, format Format) ([]*Line, error) {
+	switch format {
+	case FormatCSV:
+		return readCSV(r)
+	case FormatGeoJSON:
+		return readGeoJSON(r)
+	}
+
+	return readText(r)
+}
+
+// WriteLines writes lines in the given format.
+func WriteLines(w io.Writer, lines []*Line, format Format) error {
+	switch format {
+	case FormatCSV:
+		return writeCSV(w, lines)
+	case FormatGeoJSON:
+		return writeGeoJSON(w, lines)
+	}
+
+	return writeText(w, lines)
+}
+
+var textLineRegex = regexp.MustCompile(`^\s*(-?[0-9]+)\s*,\s*(-?[0-9]+)\s*->\s*(-?[0-9]+)\s*,\s*(-?[0-9]+)\s*$`)
+
+// parseCoordinates converts four coordinate strings to a line.
+func parseCoordinates(values []string) (*Line, error) {
+	var c [4]int
+
+	for i, value := range values {
+		v, err := strconv.Atoi(strings.TrimSpace(value))
+
+		if err != nil {
+			return nil, err
+		}
+
+		c[i] = v
+	}
+
+	return NewLine(c[0], c[1], c[2], c[3]), nil
+}
+
+func readText(r io.Reader) ([]*Line, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Split(bufio.ScanLines)
+
+	var lines []*Line
+
+	for rowIdx := 1; scanner.Scan(); rowIdx++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		matches := textLineRegex.FindStringSubmatch(scanner.Text())
+
+		if matches == nil {
+			return nil, errors.New(fmt.Sprintf("failed to parse line %d (%s)", rowIdx, scanner.Text()))
+		}
+
+		l, err := parseCoordinates(matches[1:])
+
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("failed to parse line %d (%s)", rowIdx, err.Error()))
+		}
+
+		lines = append(lines, l)
+	}
+
+	return lines, scanner.Err()
+}
+
+func writeText(w io.Writer, lines []*Line) error {
+	bw := bufio.NewWriter(w)
+
+	for _, l := range lines {
+		if _, err := fmt.Fprintf(bw, "%d,%d -> %d,%d\n", l.A.X, l.A.Y, l.B.X, l.B.Y); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+func readCSV(r io.Reader) ([]*Line, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = 4
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []*Line
+
+	for i, record := range records {
+		l, err := parseCoordinates(record)
+
+		if err != nil {
+			if i == 0 && isCSVHeader(record) {
+				continue
+			}
+
+			return nil, errors.New(fmt.Sprintf("failed to parse record %d (%s)", i+1, err.Error()))
+		}
+
+		lines = append(lines, l)
+	}
+
+	return lines, nil
+}
+
+// isCSVHeader checks if the record is the x1,y1,x2,y2 header written by writeCSV or consists of non-numeric fields only.
+func isCSVHeader(record []string) bool {
+	names := [4]string{"x1", "y1", "x2", "y2"}
+	named, nonNumeric := true, true
+
+	for i, field := range record {
+		field = strings.TrimSpace(field)
+		named = named && strings.EqualFold(field, names[i])
+
+		if _, err := strconv.ParseFloat(field, 64); err == nil {
+			nonNumeric = false
+		}
+	}
+
+	return named || nonNumeric
+}
+
+func writeCSV(w io.Writer, lines []*Line) error {
+	csvWriter := csv.NewWriter(w)
+
+	if err := csvWriter.Write([]string{"x1", "y1", "x2", "y2"}); err != nil {
+		return err
+	}
+
+	for _, l := range lines {
+		record := []string{strconv.Itoa(l.A.X), strconv.Itoa(l.A.Y), strconv.Itoa(l.B.X), strconv.Itoa(l.B.Y)}
+
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// geoJSONObject covers the GeoJSON objects that may hold line geometries.
+type geoJSONObject struct {
+	Type        string          `json:"type"`
+	Features    []geoJSONObject `json:"features"`
+	Geometry    *geoJSONObject  `json:"geometry"`
+	Geometries  []geoJSONObject `json:"geometries"`
+	Coordinates json.RawMessage `json:"coordinates"`
+}
+
+// geoJSONLineFeature is a written Feature holding a single line.
+type geoJSONLineFeature struct {
+	Type       string                 `json:"type"`
+	Properties map[string]interface{} `json:"properties"`
+	Geometry   struct {
+		Type        string    `json:"type"`
+		Coordinates [2][2]int `json:"coordinates"`
+	} `json:"geometry"`
+}
+
+func readGeoJSON(r io.Reader) ([]*Line, error) {
+	var root geoJSONObject
+
+	if err := json.NewDecoder(r).Decode(&root); err != nil {
+		return nil, err
+	}
+
+	return collectGeoJSONLines(&root, nil)
+}
+
+// collectGeoJSONLines appends segments of all LineString and MultiLineString geometries in the object. A line string
+// with n positions contributes n-1 lines.
+func collectGeoJSONLines(obj *geoJSONObject, lines []*Line) ([]*Line, error) {
+	var err error
+
+	switch obj.Type {
+	case "FeatureCollection":
+		for i := range obj.Features {
+			if lines, err = collectGeoJSONLines(&obj.Features[i], lines); err != nil {
+				return nil, err
+			}
+		}
+	case "Feature":
+		if obj.Geometry != nil {
+			return collectGeoJSONLines(obj.Geometry, lines)
+		}
+	case "GeometryCollection":
+		for i := range obj.Geometries {
+			if lines, err = collectGeoJSONLines(&obj.Geometries[i], lines); err != nil {
+				return nil, err
+			}
+		}
+	case "LineString":
+		var positions [][]float64
+
+		if err := json.Unmarshal(obj.Coordinates, &positions); err != nil {
+			return nil, errors.New(fmt.Sprintf("bad LineString coordinates (%s)", err.Error()))
+		}
+
+		return appendLineString(lines, positions)
+	case "MultiLineString":
+		var lineStrings [][][]float64
+
+		if err := json.Unmarshal(obj.Coordinates, &lineStrings); err != nil {
+			return nil, errors.New(fmt.Sprintf("bad MultiLineString coordinates (%s)", err.Error()))
+		}
+
+		for _, positions := range lineStrings {
+			if lines, err = appendLineString(lines, positions); err != nil {
+				return nil, err
+			}
+		}
+	case "Point", "MultiPoint", "Polygon", "MultiPolygon":
+		// Not vent lines.
+	default:
+		return nil, errors.New(fmt.Sprintf("unknown GeoJSON type '%s'", obj.Type))
+	}
+
+	return lines, nil
+}
+
+func appendLineString(lines []*Line, positions [][]float64) ([]*Line, error) {
+	if len(positions) < 2 {
+		return nil, errors.New(fmt.Sprintf("LineString needs at least 2 positions, got %d", len(positions)))
+	}
+
+	points := make([]Point, len(positions))
+
+	for i, position := range positions {
+		if len(position) < 2 {
+			return nil, errors.New(fmt.Sprintf("position %d has %d coordinates, expected at least 2", i, len(position)))
+		}
+
+		for _, c := range position[:2] {
+			if c != math.Trunc(c) {
+				return nil, errors.New(fmt.Sprintf("coordinate %g of position %d is not an integer", c, i))
+			}
+
+			if math.Abs(c) > math.MaxInt32 {
+				return nil, errors.New(fmt.Sprintf("coordinate %g of position %d is out of range [%d, %d]", c, i,
+					-math.MaxInt32, math.MaxInt32))
+			}
+		}
+
+		points[i] = Point{X: int(position[0]), Y: int(position[1])}
+	}
+
+	for i := 1; i < len(points); i++ {
+		lines = append(lines, &Line{A: points[i-1], B: points[i]})
+	}
+
+	return lines, nil
+}
+
+func writeGeoJSON(w io.Writer, lines []*Line) error {
+	collection := struct {
+		Type     string               `json:"type"`
+		Features []geoJSONLineFeature `json:"features"`
+	}{Type: "FeatureCollection", Features: make([]geoJSONLineFeature, len(lines))}
+
+	for i, l := range lines {
+		feature := &collection.Features[i]
+		feature.Type = "Feature"
+		feature.Properties = map[string]interface{}{"index": i}
+		feature.Geometry.Type = "LineString"
+		feature.Geometry.Coordinates = [2][2]int{{l.A.X, l.A.Y}, {l.B.X, l.B.Y}}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(collection)
+}
@@ -1,10 +1,43 @@
 package line
 
-import (
-	"math"
+// DenseGridMaxCells is the largest bounding box area for which MakeCoverageGrid picks a dense Grid.
+const DenseGridMaxCells = 1 << 24
 
-	"github.com/PrimozLavric/advent-of-code-2021/day-5/internal/util"
-)
+// CoverageGrid counts how many lines cover each cell.
+type CoverageGrid interface {
+	// ApplyLine increments coverage of the cells that intersect with the provided line rasterized with Bresenham.
+	ApplyLine(line *Line)
+	// ApplyLineWith increments coverage of the cells produced by the provided rasterizer.
+	ApplyLineWith(line *Line, rasterize Rasterizer)
+	// CountIntersections counts number of cells covered by more than one line.
+	CountIntersections() int
+}
+
+// BoxCells returns the number of cells of bounding box [low, high] and whether it is at most limit. Spans are computed
+// in uint64, so extreme coordinates cannot overflow.
+func BoxCells(low, high Point, limit uint64) (uint64, bool) {
+	spanX, spanY := uint64(high.X)-uint64(low.X), uint64(high.Y)-uint64(low.Y)
+
+	if spanX >= limit || spanY >= limit {
+		return 0, false
+	}
+
+	if spanX+1 > limit/(spanY+1) {
+		return 0, false
+	}
+
+	return (spanX + 1) * (spanY + 1), true
+}
+
+// MakeCoverageGrid creates a dense OffsetGrid if bounding box [low, high] has at most DenseGridMaxCells cells and a
+// SparseGrid otherwise.
+func MakeCoverageGrid(low, high Point) CoverageGrid {
+	if _, ok := BoxCells(low, high, DenseGridMaxCells); ok {
+		return MakeOffsetGrid(low, high)
+	}
+
+	return NewSparseGrid()
+}
 
 // Grid that is used to find intersections.
 type Grid [][]int
@@ -22,18 +55,14 @@ func MakeGrid(rows, columns int) Grid {
 
 // ApplyLine increments grid cells' value if they intersect with the provided line.
 func (grid Grid) ApplyLine(line *Line) {
-	xDiff := util.AbsDiff(line.A.X, line.B.X)
-	yDiff := util.AbsDiff(line.A.Y, line.B.Y)
-
-	maxDiff := math.Max(float64(xDiff), float64(yDiff))
-
-	for i := 0; i <= int(maxDiff); i++ {
-		t := float64(i) / maxDiff
-		x := math.Round(util.Lerp(float64(line.A.X), float64(line.B.X), t))
-		y := math.Round(util.Lerp(float64(line.A.Y), float64(line.B.Y), t))
+	grid.ApplyLineWith(line, Bresenham)
+}
 
-		grid[int(x)][int(y)] += 1
-	}
+// ApplyLineWith increments grid cells' value if they are produced by the provided rasterizer.
+func (grid Grid) ApplyLineWith(line *Line, rasterize Rasterizer) {
+	rasterize(line, func(p Point) {
+		grid[p.X][p.Y] += 1
+	})
 }
 
 // CountIntersections counts number of cells at which the applied lines intersected.
@@ -50,3 +79,42 @@ func (grid Grid) CountIntersections() int {
 
 	return isects
 }
+
+// OffsetGrid is a dense Grid whose cell [0][0] corresponds to point Origin, so it can hold negative coordinates.
+type OffsetGrid struct {
+	Origin Point
+	Cells  Grid
+}
+
+// MakeOffsetGrid creates a grid covering the bounding box [low, high].
+func MakeOffsetGrid(low, high Point) *OffsetGrid {
+	return &OffsetGrid{Origin: low, Cells: MakeGrid(high.X-low.X+1, high.Y-low.Y+1)}
+}
+
+// ApplyLine increments grid cells' value if they intersect with the provided line.
+func (grid *OffsetGrid) ApplyLine(line *Line) {
+	grid.ApplyLineWith(line, Bresenham)
+}
+
+// ApplyLineWith increments grid cells' value if they are produced by the provided rasterizer.
+func (grid *OffsetGrid) ApplyLineWith(line *Line, rasterize Rasterizer) {
+	rasterize(line, func(p Point) {
+		grid.Cells[p.X-grid.Origin.X][p.Y-grid.Origin.Y] += 1
+	})
+}
+
+// CountIntersections counts number of cells at which the applied lines intersected.
+func (grid *OffsetGrid) CountIntersections() int {
+	return grid.Cells.CountIntersections()
+}
+
+// At returns coverage of the cell at point p.
+func (grid *OffsetGrid) At(p Point) int {
+	x, y := p.X-grid.Origin.X, p.Y-grid.Origin.Y
+
+	if x < 0 || y < 0 || x >= len(grid.Cells) || y >= len(grid.Cells[x]) {
+		return 0
+	}
+
+	return grid.Cells[x][y]
+}
@@ -40,6 +40,24 @@ func (l *Line) MaxY() int {
 	return l.B.Y
 }
 
+// MinX returns minimal x position of the line.
+func (l *Line) MinX() int {
+	if l.A.X < l.B.X {
+		return l.A.X
+	}
+
+	return l.B.X
+}
+
+// MinY returns minimal y position of the line.
+func (l *Line) MinY() int {
+	if l.A.Y < l.B.Y {
+		return l.A.Y
+	}
+
+	return l.B.Y
+}
+
 // IsHorizontal checks if the line is horizontal.
 func (l *Line) IsHorizontal() bool {
 	return l.A.X == l.B.X
@@ -68,3 +86,33 @@ func FindMaxXY(lines []*Line) (int, int) {
 
 	return maxX, maxY
 }
+
+// FindBounds finds the bounding box of all lines. Coordinates may be negative.
+func FindBounds(lines []*Line) (Point, Point) {
+	if len(lines) == 0 {
+		return Point{}, Point{}
+	}
+
+	low := Point{X: lines[0].MinX(), Y: lines[0].MinY()}
+	high := Point{X: lines[0].MaxX(), Y: lines[0].MaxY()}
+
+	for _, l := range lines[1:] {
+		if l.MinX() < low.X {
+			low.X = l.MinX()
+		}
+
+		if l.MinY() < low.Y {
+			low.Y = l.MinY()
+		}
+
+		if l.MaxX() > high.X {
+			high.X = l.MaxX()
+		}
+
+		if l.MaxY() > high.Y {
+			high.Y = l.MaxY()
+		}
+	}
+
+	return low, high
+}
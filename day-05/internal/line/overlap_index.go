@@ -0,0 +1,109 @@
+package line
+
+import "sort"
+
+// CellCoverage describes a cell and the lines that cover it.
+type CellCoverage struct {
+	X        int   `json:"x"`
+	Y        int   `json:"y"`
+	Coverage int   `json:"coverage"`
+	Lines    []int `json:"lines"`
+}
+
+// LineOverlap holds the number of cells of a line that are also covered by other lines.
+type LineOverlap struct {
+	Line         int `json:"line"`
+	Cells        int `json:"cells"`
+	OverlapCells int `json:"overlap_cells"`
+}
+
+// OverlapIndex remembers which lines cover each cell, so overlaps can be traced back to the lines causing them.
+type OverlapIndex struct {
+	lines     []*Line
+	rasterize Rasterizer
+	cells     map[Point][]int
+}
+
+// NewOverlapIndex indexes the lines rasterized with the provided rasterizer. Line indices refer to the lines slice.
+func NewOverlapIndex(lines []*Line, rasterize Rasterizer) *OverlapIndex {
+	index := &OverlapIndex{lines: lines, rasterize: rasterize, cells: make(map[Point][]int)}
+
+	for i, l := range lines {
+		rasterize(l, func(p Point) {
+			index.cells[p] = append(index.cells[p], i)
+		})
+	}
+
+	return index
+}
+
+// Coverage returns number of lines that cover point p.
+func (index *OverlapIndex) Coverage(p Point) int {
+	return len(index.cells[p])
+}
+
+// LinesAt returns indices of the lines covering point p in increasing order.
+func (index *OverlapIndex) LinesAt(p Point) []int {
+	return append([]int{}, index.cells[p]...)
+}
+
+// CountIntersections counts number of cells covered by more than one line.
+func (index *OverlapIndex) CountIntersections() int {
+	return len(index.CoveredAtLeast(2))
+}
+
+// CoveredAtLeast returns cells covered by at least k lines sorted by position (x, then y).
+func (index *OverlapIndex) CoveredAtLeast(k int) []CellCoverage {
+	var cells []CellCoverage
+
+	for p, lines := range index.cells {
+		if len(lines) >= k {
+			cells = append(cells, CellCoverage{X: p.X, Y: p.Y, Coverage: len(lines), Lines: append([]int(nil), lines...)})
+		}
+	}
+
+	sort.Slice(cells, func(i, j int) bool {
+		if cells[i].X != cells[j].X {
+			return cells[i].X < cells[j].X
+		}
+
+		return cells[i].Y < cells[j].Y
+	})
+
+	return cells
+}
+
+// HotPoints returns the cells covered by more than one line sorted by decreasing coverage (ties by position). At most
+// limit cells are returned, all of them if limit is not positive.
+func (index *OverlapIndex) HotPoints(limit int) []CellCoverage {
+	cells := index.CoveredAtLeast(2)
+
+	sort.SliceStable(cells, func(i, j int) bool {
+		return cells[i].Coverage > cells[j].Coverage
+	})
+
+	if limit > 0 && limit < len(cells) {
+		cells = cells[:limit]
+	}
+
+	return cells
+}
+
+// LineOverlaps returns overlap totals of every line, in line order.
+func (index *OverlapIndex) LineOverlaps() []LineOverlap {
+	overlaps := make([]LineOverlap, len(index.lines))
+
+	for i, l := range index.lines {
+		overlaps[i].Line = i
+
+		index.rasterize(l, func(p Point) {
+			overlaps[i].Cells++
+
+			if len(index.cells[p]) > 1 {
+				overlaps[i].OverlapCells++
+			}
+		})
+	}
+
+	return overlaps
+}
@@ -0,0 +1,109 @@
+package line
+
+import (
+	"runtime"
+	"sync"
+)
+
+// parallelBatchLines is the number of lines each worker rasterizes per batch. Batching bounds the memory used for
+// buffered cells.
+const parallelBatchLines = 4096
+
+// ApplyLinesParallel applies lines like ApplyLineWith, but spreads the work over a pool of workers. The grid is split
+// into bands of columns, one per worker. Lines are first sharded across workers which rasterize them into per band
+// buffers. Then each worker applies the buffers of its own band in worker order, so no cell is ever written
+// concurrently and the result is identical to the serial one. If workers is not positive, GOMAXPROCS workers are used.
+func (grid *OffsetGrid) ApplyLinesParallel(lines []*Line, rasterize Rasterizer, workers int) {
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	width := len(grid.Cells)
+
+	if workers > width {
+		workers = width
+	}
+
+	if workers <= 1 {
+		for _, l := range lines {
+			grid.ApplyLineWith(l, rasterize)
+		}
+
+		return
+	}
+
+	bandWidth := (width + workers - 1) / workers
+
+	// buffers[w][b] holds cells of band b produced by worker w in the current batch.
+	buffers := make([][][]Point, workers)
+	for w := range buffers {
+		buffers[w] = make([][]Point, workers)
+	}
+
+	var wg sync.WaitGroup
+
+	for batchStart := 0; batchStart < len(lines); batchStart += workers * parallelBatchLines {
+		batchEnd := batchStart + workers*parallelBatchLines
+
+		if batchEnd > len(lines) {
+			batchEnd = len(lines)
+		}
+
+		// Rasterize shards of the batch.
+		shardSize := (batchEnd - batchStart + workers - 1) / workers
+
+		for w := 0; w < workers; w++ {
+			shardStart := batchStart + w*shardSize
+			shardEnd := shardStart + shardSize
+
+			if shardStart > batchEnd {
+				shardStart = batchEnd
+			}
+
+			if shardEnd > batchEnd {
+				shardEnd = batchEnd
+			}
+
+			wg.Add(1)
+
+			go func(bands [][]Point, shard []*Line) {
+				defer wg.Done()
+
+				for b := range bands {
+					bands[b] = bands[b][:0]
+				}
+
+				for _, l := range shard {
+					rasterize(l, func(p Point) {
+						b := (p.X - grid.Origin.X) / bandWidth
+						bands[b] = append(bands[b], p)
+					})
+				}
+			}(buffers[w], lines[shardStart:shardEnd])
+		}
+
+		wg.Wait()
+
+		// Apply buffered cells band by band.
+		for b := 0; b < workers; b++ {
+			wg.Add(1)
+
+			go func(b int) {
+				defer wg.Done()
+
+				for w := 0; w < workers; w++ {
+					for _, p := range buffers[w][b] {
+						grid.Cells[p.X-grid.Origin.X][p.Y-grid.Origin.Y] += 1
+					}
+				}
+			}(b)
+		}
+
+		wg.Wait()
+	}
+}
+
+// ApplyLinesParallel applies lines with a pool of workers. See OffsetGrid.ApplyLinesParallel.
+func (grid Grid) ApplyLinesParallel(lines []*Line, rasterize Rasterizer, workers int) {
+	(&OffsetGrid{Cells: grid}).ApplyLinesParallel(lines, rasterize, workers)
+}
@@ -0,0 +1,100 @@
+package line
+
+import "github.com/PrimozLavric/advent-of-code-2021/day-5/internal/util"
+
+// Rasterizer calls visit for every grid cell that is part of the rasterized line. Cell (x, y) is the unit square
+// centered at lattice point (x, y).
+type Rasterizer func(line *Line, visit func(p Point))
+
+// Bresenham rasterizes the line with exactly one cell per step along its major axis (x if |dx| >= |dy|, y otherwise).
+// At every major coordinate it visits the lattice point nearest to the exact line, ties are rounded towards positive
+// infinity, so the result does not depend on the direction of the line. Horizontal, vertical and 45-degree lines are
+// rasterized to exactly the lattice points lying on them. Only integer arithmetic is used.
+func Bresenham(line *Line, visit func(p Point)) {
+	dx := line.B.X - line.A.X
+	dy := line.B.Y - line.A.Y
+
+	if util.AbsDiff(line.A.X, line.B.X) >= util.AbsDiff(line.A.Y, line.B.Y) {
+		bresenham(line.A.X, line.A.Y, dx, dy, func(major, minor int) { visit(Point{X: major, Y: minor}) })
+	} else {
+		bresenham(line.A.Y, line.A.X, dy, dx, func(major, minor int) { visit(Point{X: minor, Y: major}) })
+	}
+}
+
+// bresenham walks from (major0, minor0) along the major axis for |dMajor| steps. At step i minor offset is
+// round_half_up(i * dMinor / |dMajor|), maintained incrementally as quotient and remainder of
+// (2 * i * dMinor + n) / (2 * n) where n = |dMajor|.
+func bresenham(major0, minor0, dMajor, dMinor int, visit func(major, minor int)) {
+	n := dMajor
+	step := 1
+
+	if n < 0 {
+		n = -n
+		step = -1
+	}
+
+	if n == 0 {
+		visit(major0, minor0)
+		return
+	}
+
+	denominator := 2 * n
+	quotient, remainder := 0, n
+
+	for i := 0; i <= n; i++ {
+		visit(major0+step*i, minor0+quotient)
+
+		remainder += 2 * dMinor
+
+		// |dMinor| <= n, so at most one correction is needed.
+		if remainder >= denominator {
+			remainder -= denominator
+			quotient++
+		} else if remainder < 0 {
+			remainder += denominator
+			quotient--
+		}
+	}
+}
+
+// Supercover rasterizes the line to every cell whose closed unit square intersects the line. When the line passes
+// exactly through a corner shared by four cells all of them are visited. Only integer arithmetic is used.
+func Supercover(line *Line, visit func(p Point)) {
+	nx := util.AbsDiff(line.A.X, line.B.X)
+	ny := util.AbsDiff(line.A.Y, line.B.Y)
+	sx, sy := 1, 1
+
+	if line.B.X < line.A.X {
+		sx = -1
+	}
+
+	if line.B.Y < line.A.Y {
+		sy = -1
+	}
+
+	p := line.A
+	visit(p)
+
+	for ix, iy := 0, 0; ix < nx || iy < ny; {
+		// Compare distances (scaled by 2 * nx * ny) to the next vertical and horizontal cell boundary.
+		decision := (1+2*ix)*ny - (1+2*iy)*nx
+
+		if decision == 0 {
+			// Line passes through the corner, so it also touches both side cells.
+			visit(Point{X: p.X + sx, Y: p.Y})
+			visit(Point{X: p.X, Y: p.Y + sy})
+			p.X += sx
+			p.Y += sy
+			ix++
+			iy++
+		} else if decision < 0 {
+			p.X += sx
+			ix++
+		} else {
+			p.Y += sy
+			iy++
+		}
+
+		visit(p)
+	}
+}
@@ -0,0 +1,115 @@
+package line
+
+import "github.com/PrimozLavric/advent-of-code-2021/day-5/internal/util"
+
+// SparseGrid is used to find intersections of lines spanning huge coordinate ranges. Rasterized cells are compressed
+// into runs of cells that advance by the same unit step, so horizontal, vertical and 45-degree lines take constant
+// memory regardless of their length. Intersections are counted with FindIntersections.
+type SparseGrid struct {
+	// runs lines whose lattice points are exactly the cells of a run.
+	runs []*Line
+}
+
+// NewSparseGrid creates an empty SparseGrid.
+func NewSparseGrid() *SparseGrid {
+	return &SparseGrid{}
+}
+
+// ApplyLine increments coverage of the cells that intersect with the provided line. Bresenham rasterizes horizontal,
+// vertical and 45-degree lines to exactly their lattice points, so such lines are stored as a single run without
+// visiting their cells.
+func (grid *SparseGrid) ApplyLine(line *Line) {
+	if util.AbsDiff(line.A.X, line.B.X) == util.AbsDiff(line.A.Y, line.B.Y) || line.IsHorizontal() ||
+		line.IsVertical() {
+		grid.runs = append(grid.runs, NewLine(line.A.X, line.A.Y, line.B.X, line.B.Y))
+		return
+	}
+
+	grid.ApplyLineWith(line, Bresenham)
+}
+
+// ApplyLineWith increments coverage of the cells produced by the provided rasterizer.
+func (grid *SparseGrid) ApplyLineWith(line *Line, rasterize Rasterizer) {
+	var start, last, step Point
+	length := 0
+
+	flush := func() {
+		if length > 0 {
+			grid.runs = append(grid.runs, NewLine(start.X, start.Y, last.X, last.Y))
+		}
+	}
+
+	rasterize(line, func(p Point) {
+		d := Point{X: p.X - last.X, Y: p.Y - last.Y}
+
+		switch {
+		case length == 1 && isUnitStep(d):
+			step = d
+		case length > 1 && d == step:
+		default:
+			flush()
+			start, length = p, 0
+		}
+
+		last = p
+		length++
+	})
+
+	flush()
+}
+
+// isUnitStep checks whether d moves to one of the 8 neighbouring cells.
+func isUnitStep(d Point) bool {
+	return d != Point{} && d.X >= -1 && d.X <= 1 && d.Y >= -1 && d.Y <= 1
+}
+
+// CountIntersections counts number of cells at which the applied lines intersected. Runs are translated to the
+// coordinate range of FindIntersections. Only if they span more than that range, cells are counted one by one.
+func (grid *SparseGrid) CountIntersections() int {
+	if len(grid.runs) == 0 {
+		return 0
+	}
+
+	low, high := FindBounds(grid.runs)
+	spanX, spanY := uint64(high.X)-uint64(low.X), uint64(high.Y)-uint64(low.Y)
+
+	if spanX > 2*MaxSweepCoordinate || spanY > 2*MaxSweepCoordinate {
+		return grid.countCells()
+	}
+
+	mid := Point{X: low.X + int(spanX/2), Y: low.Y + int(spanY/2)}
+	translated := make([]*Line, len(grid.runs))
+
+	for i, run := range grid.runs {
+		translated[i] = NewLine(run.A.X-mid.X, run.A.Y-mid.Y, run.B.X-mid.X, run.B.Y-mid.Y)
+	}
+
+	res, err := FindIntersections(translated)
+
+	if err != nil {
+		return grid.countCells()
+	}
+
+	return res.LatticeOverlapCount
+}
+
+// countCells counts intersections by storing coverage of every cell of every run.
+func (grid *SparseGrid) countCells() int {
+	coverage := make(map[Point]int)
+
+	for _, run := range grid.runs {
+		Bresenham(run, func(p Point) {
+			coverage[p]++
+		})
+	}
+
+	isects := 0
+
+	for _, value := range coverage {
+		if value > 1 {
+			isects++
+		}
+	}
+
+	return isects
+}
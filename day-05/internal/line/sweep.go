@@ -0,0 +1,561 @@
+package line
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-5/internal/util"
+)
+
+// MaxSweepCoordinate is the largest absolute coordinate accepted by FindIntersections. It keeps all integer
+// predicates within int64.
+const MaxSweepCoordinate = 1 << 29
+
+// Intersection is a point where two or more lines meet. Coordinates are exact rationals.
+type Intersection struct {
+	X *big.Rat
+	Y *big.Rat
+	// Lines indices of all lines that contain the point, sorted ascending.
+	Lines []int
+}
+
+// Overlap is a sub-segment shared by two collinear lines. A and B are equal if the lines only touch at an endpoint.
+type Overlap struct {
+	A     Point
+	B     Point
+	Lines [2]int
+}
+
+// SweepResult contains all intersections and overlaps of a set of lines.
+type SweepResult struct {
+	Intersections []Intersection
+	Overlaps      []Overlap
+	// LatticeOverlapCount number of lattice points that lie on at least two lines. For horizontal, vertical and
+	// 45-degree lines it equals Grid.CountIntersections.
+	LatticeOverlapCount int
+}
+
+// FindIntersections finds every intersection point and collinear overlap between the lines.
+//
+// Lines that lie on the same supporting line are handled exactly in one dimension: they are parametrized by integer
+// steps along the primitive direction of the supporting line, their pairwise overlaps are reported and they are
+// merged into disjoint components. Crossings between components of different supporting lines (and zero-length
+// lines) are then found with a Bentley-Ottmann sweep over increasing x. Event points are exact rationals, the sweep
+// status is a sorted slice.
+func FindIntersections(lines []*Line) (*SweepResult, error) {
+	for i, l := range lines {
+		for _, c := range [4]int{l.A.X, l.A.Y, l.B.X, l.B.Y} {
+			if c > MaxSweepCoordinate || c < -MaxSweepCoordinate {
+				return nil, errors.New(fmt.Sprintf("line %d has coordinate %d out of range [-%d, %d]", i, c,
+					MaxSweepCoordinate, MaxSweepCoordinate))
+			}
+		}
+	}
+
+	res := SweepResult{}
+	s := newSweep()
+
+	// Group lines by their supporting line.
+	groups := make(map[[3]int]*collinearGroup)
+	var groupKeys [][3]int
+
+	for i, l := range lines {
+		if l.A == l.B {
+			s.addPoint(l.A, i)
+			continue
+		}
+
+		key, dir := supportingLine(l)
+		g, ok := groups[key]
+
+		if !ok {
+			g = &collinearGroup{base: l.A, dir: dir}
+			groups[key] = g
+			groupKeys = append(groupKeys, key)
+		}
+
+		g.add(l, i)
+	}
+
+	for _, key := range groupKeys {
+		g := groups[key]
+		g.sortMembers()
+		res.Overlaps = append(res.Overlaps, g.overlaps()...)
+		res.LatticeOverlapCount += g.computeCoverage()
+
+		for _, c := range g.components() {
+			s.addSegment(c)
+		}
+	}
+
+	isects, latticeCount := s.run()
+	res.Intersections = isects
+	res.LatticeOverlapCount += latticeCount
+
+	return &res, nil
+}
+
+// supportingLine returns the key identifying the supporting line of a non-degenerate line together with its
+// primitive direction. Direction points towards increasing x (or increasing y for vertical lines).
+func supportingLine(l *Line) ([3]int, Point) {
+	dx := l.B.X - l.A.X
+	dy := l.B.Y - l.A.Y
+	g := gcd(util.AbsDiff(dx, 0), util.AbsDiff(dy, 0))
+	dir := Point{X: dx / g, Y: dy / g}
+
+	if dir.X < 0 || (dir.X == 0 && dir.Y < 0) {
+		dir = Point{X: -dir.X, Y: -dir.Y}
+	}
+
+	// All points on the line share the same value of the cross product with the direction.
+	return [3]int{dir.X, dir.Y, dir.X*l.A.Y - dir.Y*l.A.X}, dir
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+
+	return a
+}
+
+// member is a line of a collinear group, spanning integer steps [t0, t1] from the group's base point.
+type member struct {
+	lineIdx int
+	t0      int
+	t1      int
+}
+
+// collinearGroup contains lines that lie on the same supporting line.
+type collinearGroup struct {
+	base    Point
+	dir     Point
+	members []member
+	// multiCovered sorted disjoint step intervals covered by at least two members.
+	multiCovered [][2]int
+}
+
+// param returns the number of steps from the base point to the lattice point p on the supporting line.
+func (g *collinearGroup) param(p Point) int {
+	if g.dir.X != 0 {
+		return (p.X - g.base.X) / g.dir.X
+	}
+
+	return (p.Y - g.base.Y) / g.dir.Y
+}
+
+// point returns the lattice point t steps from the base point.
+func (g *collinearGroup) point(t int) Point {
+	return Point{X: g.base.X + t*g.dir.X, Y: g.base.Y + t*g.dir.Y}
+}
+
+func (g *collinearGroup) add(l *Line, lineIdx int) {
+	t0, t1 := g.param(l.A), g.param(l.B)
+
+	if t0 > t1 {
+		t0, t1 = t1, t0
+	}
+
+	g.members = append(g.members, member{lineIdx: lineIdx, t0: t0, t1: t1})
+}
+
+// sortMembers orders members by their first step. Must be called after all members are added.
+func (g *collinearGroup) sortMembers() {
+	sort.SliceStable(g.members, func(i, j int) bool { return g.members[i].t0 < g.members[j].t0 })
+}
+
+// overlaps returns pairwise overlaps of the members.
+func (g *collinearGroup) overlaps() []Overlap {
+	var overlaps []Overlap
+	var active []member
+
+	for _, m := range g.members {
+		stillActive := active[:0]
+
+		for _, a := range active {
+			if a.t1 < m.t0 {
+				continue
+			}
+
+			stillActive = append(stillActive, a)
+			end := a.t1
+
+			if m.t1 < end {
+				end = m.t1
+			}
+
+			overlaps = append(overlaps, Overlap{A: g.point(m.t0), B: g.point(end), Lines: [2]int{a.lineIdx, m.lineIdx}})
+		}
+
+		active = append(stillActive, m)
+	}
+
+	return overlaps
+}
+
+// computeCoverage finds step intervals covered by at least two members and returns the number of lattice points in
+// them.
+func (g *collinearGroup) computeCoverage() int {
+	type event struct {
+		t     int
+		delta int
+	}
+
+	events := make([]event, 0, 2*len(g.members))
+	for _, m := range g.members {
+		events = append(events, event{t: m.t0, delta: 1}, event{t: m.t1 + 1, delta: -1})
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].t < events[j].t })
+
+	count := 0
+	lattice := 0
+	start := 0
+
+	for i := 0; i < len(events); {
+		t := events[i].t
+		prev := count
+
+		for ; i < len(events) && events[i].t == t; i++ {
+			count += events[i].delta
+		}
+
+		if prev < 2 && count >= 2 {
+			start = t
+		} else if prev >= 2 && count < 2 {
+			g.multiCovered = append(g.multiCovered, [2]int{start, t - 1})
+			lattice += t - start
+		}
+	}
+
+	return lattice
+}
+
+// multiCoveredAt reports whether the lattice point p on the supporting line is covered by at least two members.
+func (g *collinearGroup) multiCoveredAt(p Point) bool {
+	t := g.param(p)
+	idx := sort.Search(len(g.multiCovered), func(i int) bool { return g.multiCovered[i][1] >= t })
+
+	return idx < len(g.multiCovered) && g.multiCovered[idx][0] <= t
+}
+
+// components merges members into disjoint segments. Members that touch are merged as well.
+func (g *collinearGroup) components() []*sweepSegment {
+	var components []*sweepSegment
+	var current *sweepSegment
+	end := 0
+
+	for _, m := range g.members {
+		if current == nil || m.t0 > end {
+			current = &sweepSegment{group: g, t0: m.t0}
+			components = append(components, current)
+			end = m.t1
+		}
+
+		if m.t1 > end {
+			end = m.t1
+		}
+
+		current.members = append(current.members, m)
+		current.a = g.point(current.t0)
+		current.b = g.point(end)
+	}
+
+	return components
+}
+
+// sweepSegment is a component of a collinear group, oriented from a to b in sweep order.
+type sweepSegment struct {
+	group   *collinearGroup
+	t0      int
+	a       Point
+	b       Point
+	members []member
+}
+
+func (seg *sweepSegment) vertical() bool {
+	return seg.a.X == seg.b.X
+}
+
+// linesAt appends indices of the member lines that contain point p on the segment.
+func (seg *sweepSegment) linesAt(p ratPoint, lines []int) []int {
+	// Step parameter of p along the supporting line.
+	var t *big.Rat
+	if seg.group.dir.X != 0 {
+		t = new(big.Rat).Sub(p.x, ratInt(seg.group.base.X))
+		t.Quo(t, ratInt(seg.group.dir.X))
+	} else {
+		t = new(big.Rat).Sub(p.y, ratInt(seg.group.base.Y))
+		t.Quo(t, ratInt(seg.group.dir.Y))
+	}
+
+	for _, m := range seg.members {
+		if t.Cmp(ratInt(m.t0)) >= 0 && t.Cmp(ratInt(m.t1)) <= 0 {
+			lines = append(lines, m.lineIdx)
+		}
+	}
+
+	return lines
+}
+
+// sideOf returns the sign of (y of the segment at x = p.x) - p.y. Vertical segments in the sweep status always
+// contain the current event point.
+func (seg *sweepSegment) sideOf(p ratPoint) int {
+	if seg.vertical() {
+		return 0
+	}
+
+	dx := seg.b.X - seg.a.X
+	dy := seg.b.Y - seg.a.Y
+
+	// y(p.x) - p.y = (a.y - p.y) + (p.x - a.x) * dy / dx, multiplied by dx > 0.
+	lhs := new(big.Rat).Sub(ratInt(seg.a.Y), p.y)
+	lhs.Mul(lhs, ratInt(dx))
+	rhs := new(big.Rat).Sub(p.x, ratInt(seg.a.X))
+	rhs.Mul(rhs, ratInt(dy))
+
+	return lhs.Add(lhs, rhs).Sign()
+}
+
+// compareSlopes orders segments as they appear just right of a common point. Vertical segments come last.
+func compareSlopes(a, b *sweepSegment) int {
+	if a.vertical() || b.vertical() {
+		if a.vertical() && b.vertical() {
+			return 0
+		} else if a.vertical() {
+			return 1
+		}
+
+		return -1
+	}
+
+	lhs := (a.b.Y - a.a.Y) * (b.b.X - b.a.X)
+	rhs := (b.b.Y - b.a.Y) * (a.b.X - a.a.X)
+
+	if lhs < rhs {
+		return -1
+	} else if lhs > rhs {
+		return 1
+	}
+
+	return 0
+}
+
+// intersect returns the intersection point of two non-collinear segments.
+func intersect(s1, s2 *sweepSegment) (ratPoint, bool) {
+	rx, ry := s1.b.X-s1.a.X, s1.b.Y-s1.a.Y
+	sx, sy := s2.b.X-s2.a.X, s2.b.Y-s2.a.Y
+	denom := rx*sy - ry*sx
+
+	if denom == 0 {
+		return ratPoint{}, false
+	}
+
+	qx, qy := s2.a.X-s1.a.X, s2.a.Y-s1.a.Y
+	tNum := qx*sy - qy*sx
+	uNum := qx*ry - qy*rx
+
+	if denom < 0 {
+		denom, tNum, uNum = -denom, -tNum, -uNum
+	}
+
+	if tNum < 0 || tNum > denom || uNum < 0 || uNum > denom {
+		return ratPoint{}, false
+	}
+
+	t := big.NewRat(int64(tNum), int64(denom))
+	x := new(big.Rat).Mul(t, ratInt(rx))
+	x.Add(x, ratInt(s1.a.X))
+	y := new(big.Rat).Mul(t, ratInt(ry))
+	y.Add(y, ratInt(s1.a.Y))
+
+	return ratPoint{x: x, y: y}, true
+}
+
+func ratInt(v int) *big.Rat {
+	return new(big.Rat).SetInt64(int64(v))
+}
+
+// ratPoint is a point with exact rational coordinates.
+type ratPoint struct {
+	x *big.Rat
+	y *big.Rat
+}
+
+func newRatPoint(p Point) ratPoint {
+	return ratPoint{x: ratInt(p.X), y: ratInt(p.Y)}
+}
+
+// compare orders points by x and then by y.
+func (p ratPoint) compare(other ratPoint) int {
+	if c := p.x.Cmp(other.x); c != 0 {
+		return c
+	}
+
+	return p.y.Cmp(other.y)
+}
+
+func (p ratPoint) key() string {
+	return p.x.RatString() + "," + p.y.RatString()
+}
+
+// lattice returns the point as a lattice point if both coordinates are integers.
+func (p ratPoint) lattice() (Point, bool) {
+	if !p.x.IsInt() || !p.y.IsInt() {
+		return Point{}, false
+	}
+
+	return Point{X: int(p.x.Num().Int64()), Y: int(p.y.Num().Int64())}, true
+}
+
+// sweepEvent is an event point with the segments that start and the zero-length lines that lie in it.
+type sweepEvent struct {
+	p      ratPoint
+	starts []*sweepSegment
+	points []int
+}
+
+// eventQueue is a min-heap of events ordered by point.
+type eventQueue []*sweepEvent
+
+func (q eventQueue) Len() int            { return len(q) }
+func (q eventQueue) Less(i, j int) bool  { return q[i].p.compare(q[j].p) < 0 }
+func (q eventQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *eventQueue) Push(x interface{}) { *q = append(*q, x.(*sweepEvent)) }
+
+func (q *eventQueue) Pop() interface{} {
+	old := *q
+	e := old[len(old)-1]
+	*q = old[:len(old)-1]
+
+	return e
+}
+
+// sweep is the state of the Bentley-Ottmann sweep.
+type sweep struct {
+	queue  eventQueue
+	events map[string]*sweepEvent
+	status []*sweepSegment
+}
+
+func newSweep() *sweep {
+	return &sweep{events: make(map[string]*sweepEvent)}
+}
+
+// event returns the event at point p, creating it if needed.
+func (s *sweep) event(p ratPoint) *sweepEvent {
+	key := p.key()
+	e, ok := s.events[key]
+
+	if !ok {
+		e = &sweepEvent{p: p}
+		s.events[key] = e
+		heap.Push(&s.queue, e)
+	}
+
+	return e
+}
+
+func (s *sweep) addPoint(p Point, lineIdx int) {
+	e := s.event(newRatPoint(p))
+	e.points = append(e.points, lineIdx)
+}
+
+func (s *sweep) addSegment(seg *sweepSegment) {
+	e := s.event(newRatPoint(seg.a))
+	e.starts = append(e.starts, seg)
+	s.event(newRatPoint(seg.b))
+}
+
+// findNewEvent schedules intersection of a and b if it lies after the current event point p.
+func (s *sweep) findNewEvent(a, b *sweepSegment, p ratPoint) {
+	q, ok := intersect(a, b)
+
+	if ok && q.compare(p) > 0 {
+		s.event(q)
+	}
+}
+
+// run processes all events and returns intersections together with the correction of the lattice overlap count.
+// Lattice intersection points that are not covered by collinear overlaps are added and points that were counted by
+// overlaps of several supporting lines are deduplicated.
+func (s *sweep) run() ([]Intersection, int) {
+	var isects []Intersection
+	lattice := 0
+
+	for s.queue.Len() > 0 {
+		e := heap.Pop(&s.queue).(*sweepEvent)
+		delete(s.events, e.p.key())
+		p := e.p
+
+		// Segments that contain p are contiguous in the status.
+		lo := sort.Search(len(s.status), func(i int) bool { return s.status[i].sideOf(p) >= 0 })
+		hi := lo
+
+		for hi < len(s.status) && s.status[hi].sideOf(p) == 0 {
+			hi++
+		}
+
+		pEnd, pIsLattice := p.lattice()
+		var continuing []*sweepSegment
+		involved := len(e.starts) + len(e.points) + hi - lo
+
+		for _, seg := range s.status[lo:hi] {
+			if !pIsLattice || seg.b != pEnd {
+				continuing = append(continuing, seg)
+			}
+		}
+
+		if involved > 1 {
+			isect := Intersection{X: p.x, Y: p.y, Lines: append([]int{}, e.points...)}
+			// Number of collinear groups that already counted p as multi-covered lattice point.
+			coveredBy := 0
+
+			for _, seg := range append(append([]*sweepSegment{}, s.status[lo:hi]...), e.starts...) {
+				isect.Lines = seg.linesAt(p, isect.Lines)
+
+				if pIsLattice && seg.group.multiCoveredAt(pEnd) {
+					coveredBy++
+				}
+			}
+
+			sort.Ints(isect.Lines)
+			isects = append(isects, isect)
+
+			if pIsLattice {
+				lattice += 1 - coveredBy
+			}
+		}
+
+		// Replace segments that contain p with the ones that continue after it, ordered as just right of p.
+		inserted := append(continuing, e.starts...)
+		sort.SliceStable(inserted, func(i, j int) bool { return compareSlopes(inserted[i], inserted[j]) < 0 })
+
+		status := make([]*sweepSegment, 0, len(s.status)-(hi-lo)+len(inserted))
+		status = append(status, s.status[:lo]...)
+		status = append(status, inserted...)
+		status = append(status, s.status[hi:]...)
+		s.status = status
+
+		if len(inserted) == 0 {
+			if lo > 0 && lo < len(s.status) {
+				s.findNewEvent(s.status[lo-1], s.status[lo], p)
+			}
+
+			continue
+		}
+
+		if lo > 0 {
+			s.findNewEvent(s.status[lo-1], s.status[lo], p)
+		}
+
+		if last := lo + len(inserted); last < len(s.status) {
+			s.findNewEvent(s.status[last-1], s.status[last], p)
+		}
+	}
+
+	return isects, lattice
+}
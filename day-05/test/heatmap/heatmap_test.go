@@ -0,0 +1,125 @@
+package test
+
+import (
+	"bytes"
+	"fmt"
+	"image/color"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-5/internal/heatmap"
+	"github.com/PrimozLavric/advent-of-code-2021/day-5/internal/line"
+)
+
+func exampleGrid() (*line.OffsetGrid, []*line.Line) {
+	lines := []*line.Line{line.NewLine(-1, 0, 2, 0), line.NewLine(0, -1, 0, 1)}
+	low, high := line.FindBounds(lines)
+	grid := line.MakeOffsetGrid(low, high)
+
+	for _, l := range lines {
+		grid.ApplyLine(l)
+	}
+
+	return grid, lines
+}
+
+func TestRampAt(t *testing.T) {
+	ramp, err := heatmap.ParseRamp("#000000, #ff0000,#ffff00")
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	testCases := map[[2]int]color.RGBA{
+		{0, 4}: {A: 0xff},
+		{1, 4}: {R: 0x80, A: 0xff},
+		{2, 4}: {R: 0xff, A: 0xff},
+		{3, 4}: {R: 0xff, G: 0x80, A: 0xff},
+		{4, 4}: {R: 0xff, G: 0xff, A: 0xff},
+		{1, 0}: {A: 0xff},
+	}
+
+	for args, expected := range testCases {
+		if actual := ramp.At(args[0], args[1]); actual != expected {
+			t.Errorf("At(%d, %d): expected %v, actual %v", args[0], args[1], expected, actual)
+		}
+	}
+
+	for _, bad := range []string{"#000000", "#000000,#12345", "#000000,#gggggg"} {
+		if _, err := heatmap.ParseRamp(bad); err == nil {
+			t.Errorf("expected error for ramp '%s', but none occurred", bad)
+		}
+	}
+}
+
+func TestImageEncoders(t *testing.T) {
+	grid, _ := exampleGrid()
+	img := heatmap.Image(grid.Cells, heatmap.DefaultRamp, 2)
+
+	if img.Bounds().Dx() != 8 || img.Bounds().Dy() != 6 {
+		t.Fatalf("expected 8x6 image, actual %dx%d", img.Bounds().Dx(), img.Bounds().Dy())
+	}
+
+	// Crossing at (0, 0) is the hottest cell, so every pixel of its square has the last ramp color.
+	hottest := heatmap.DefaultRamp[len(heatmap.DefaultRamp)-1]
+	for _, p := range [][2]int{{2, 2}, {3, 3}} {
+		if img.RGBAAt(p[0], p[1]) != hottest {
+			t.Errorf("expected pixel %v to be %v, actual %v", p, hottest, img.RGBAAt(p[0], p[1]))
+		}
+	}
+
+	if img.RGBAAt(0, 0) != heatmap.DefaultRamp[0] {
+		t.Errorf("expected uncovered pixel to be %v, actual %v", heatmap.DefaultRamp[0], img.RGBAAt(0, 0))
+	}
+
+	var buf bytes.Buffer
+
+	if err := heatmap.WritePNG(&buf, img); err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	decoded, err := png.Decode(&buf)
+
+	if err != nil || decoded.Bounds() != img.Bounds() {
+		t.Errorf("PNG round trip failed (%v)", err)
+	}
+
+	buf.Reset()
+
+	if err := heatmap.WritePPM(&buf, img); err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	header := "P6\n8 6\n255\n"
+	if !strings.HasPrefix(buf.String(), header) || buf.Len() != len(header)+8*6*3 {
+		t.Errorf("unexpected PPM layout (%d bytes)", buf.Len())
+	}
+}
+
+func TestWriteSVG(t *testing.T) {
+	grid, lines := exampleGrid()
+	var buf bytes.Buffer
+
+	if err := heatmap.WriteSVG(&buf, grid.Cells, grid.Origin, lines, heatmap.DefaultRamp, 10, color.RGBA{B: 0xff}); err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	svg := buf.String()
+
+	if strings.Count(svg, "<rect x=") != 6 {
+		t.Errorf("expected 6 covered cells, actual %d", strings.Count(svg, "<rect x="))
+	}
+
+	// Lines are drawn through cell centers relative to the grid origin (-1, -1).
+	for _, expected := range []string{`<line x1="5" y1="15" x2="35" y2="15">`, `<line x1="15" y1="5" x2="15" y2="25">`,
+		"<title>0,0: 2</title>"} {
+		if !strings.Contains(svg, expected) {
+			t.Errorf("expected SVG to contain %s", expected)
+		}
+	}
+
+	if !strings.HasPrefix(svg, fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d"`, 40, 30)) {
+		t.Errorf("unexpected SVG header")
+	}
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-5/internal/line"
+)
+
+func TestReadLinesFormats(t *testing.T) {
+	testCases := map[line.Format]string{
+		line.FormatText: "-3,4 -> 5,-6\n\n  0,0->0,-2 \n",
+		line.FormatCSV:  "x1,y1,x2,y2\n-3, 4, 5, -6\n0,0,0,-2\n",
+		line.FormatGeoJSON: `{"type": "FeatureCollection", "features": [
+			{"type": "Feature", "properties": null,
+			 "geometry": {"type": "LineString", "coordinates": [[-3, 4], [5, -6]]}},
+			{"type": "Feature", "properties": {},
+			 "geometry": {"type": "MultiLineString", "coordinates": [[[0, 0], [0, -2]]]}}]}`,
+	}
+
+	expected := []line.Line{*line.NewLine(-3, 4, 5, -6), *line.NewLine(0, 0, 0, -2)}
+
+	for format, input := range testCases {
+		lines, err := line.ReadLines(strings.NewReader(input), format)
+
+		if err != nil {
+			t.Fatalf("format %d: encountered error (%s)", format, err.Error())
+		}
+
+		if len(lines) != len(expected) {
+			t.Fatalf("format %d: expected %d lines, actual %d", format, len(expected), len(lines))
+		}
+
+		for i := range expected {
+			if *lines[i] != expected[i] {
+				t.Errorf("format %d: expected line %+v, actual %+v", format, expected[i], *lines[i])
+			}
+		}
+
+		// Lines survive a write and read round trip.
+		var buf bytes.Buffer
+
+		if err := line.WriteLines(&buf, lines, format); err != nil {
+			t.Fatalf("format %d: encountered error (%s) while writing", format, err.Error())
+		}
+
+		reread, err := line.ReadLines(&buf, format)
+
+		if err != nil || len(reread) != len(lines) || *reread[0] != *lines[0] || *reread[1] != *lines[1] {
+			t.Errorf("format %d: round trip changed lines (%v)", format, err)
+		}
+	}
+}
+
+func TestReadLinesErrors(t *testing.T) {
+	testCases := map[line.Format]string{
+		line.FormatText:    "1,2 -> 3,4\n1,2 => 3,4\n",
+		line.FormatCSV:     "1,2,3,4\n1,x,3,4\n",
+		line.FormatGeoJSON: `{"type": "LineString", "coordinates": [[0.5, 1], [2, 3]]}`,
+	}
+
+	for format, input := range testCases {
+		if _, err := line.ReadLines(strings.NewReader(input), format); err == nil {
+			t.Errorf("format %d: expected error, but none occurred", format)
+		}
+	}
+
+	// Malformed first data row is an error rather than a skipped header.
+	if _, err := line.ReadLines(strings.NewReader("1,x,3,4\n2,2,3,3\n"), line.FormatCSV); err == nil {
+		t.Errorf("expected error for malformed first CSV row")
+	}
+
+	lines, err := line.ReadLines(strings.NewReader("from_x,from_y,to_x,to_y\n2,2,3,3\n"), line.FormatCSV)
+
+	if err != nil || len(lines) != 1 {
+		t.Errorf("expected non-numeric header to be skipped, got %d lines (%v)", len(lines), err)
+	}
+
+	_, err = line.ReadLines(strings.NewReader(`{"type": "LineString", "coordinates": [[5e9, 1], [2, 3]]}`),
+		line.FormatGeoJSON)
+
+	if err == nil || !strings.Contains(err.Error(), "out of range") {
+		t.Errorf("expected out of range error, actual %v", err)
+	}
+
+	if line.DetectFormat("vents.geojson") != line.FormatGeoJSON || line.DetectFormat("input.txt") != line.FormatText {
+		t.Errorf("format detection by extension failed")
+	}
+}
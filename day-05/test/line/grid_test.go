@@ -0,0 +1,148 @@
+package test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-5/internal/line"
+)
+
+func exampleLines() []*line.Line {
+	return []*line.Line{
+		line.NewLine(0, 9, 5, 9),
+		line.NewLine(8, 0, 0, 8),
+		line.NewLine(9, 4, 3, 4),
+		line.NewLine(2, 2, 2, 1),
+		line.NewLine(7, 0, 7, 4),
+		line.NewLine(6, 4, 2, 0),
+		line.NewLine(0, 9, 2, 9),
+		line.NewLine(3, 4, 1, 4),
+		line.NewLine(0, 0, 8, 8),
+		line.NewLine(5, 5, 8, 2),
+	}
+}
+
+func TestDenseAndSparseGrid(t *testing.T) {
+	lines := exampleLines()
+	maxX, maxY := line.FindMaxXY(lines)
+
+	grids := map[string]line.CoverageGrid{
+		"dense":  line.MakeGrid(maxX+1, maxY+1),
+		"sparse": line.NewSparseGrid(),
+	}
+
+	for name, grid := range grids {
+		for _, l := range lines {
+			grid.ApplyLine(l)
+		}
+
+		if grid.CountIntersections() != 12 {
+			t.Errorf("%s grid: expected 12 intersections, actual %d", name, grid.CountIntersections())
+		}
+	}
+}
+
+func TestMakeCoverageGrid(t *testing.T) {
+	dense := line.MakeCoverageGrid(line.Point{X: -500, Y: -500}, line.Point{X: 499, Y: 499})
+
+	if _, ok := dense.(*line.OffsetGrid); !ok {
+		t.Fatalf("expected dense grid for small bounding box")
+	}
+
+	dense.ApplyLine(line.NewLine(-500, -500, 499, 499))
+	dense.ApplyLine(line.NewLine(-500, 499, 499, -500))
+	dense.ApplyLine(line.NewLine(-3, 0, 3, 0))
+
+	// Diagonals cross between lattice points, horizontal line crosses them at (0, 0) and (-1, 0).
+	if dense.CountIntersections() != 2 {
+		t.Errorf("expected 2 intersections, actual %d", dense.CountIntersections())
+	}
+
+	grid := line.MakeCoverageGrid(line.Point{}, line.Point{X: 500000000, Y: 500000000})
+
+	if _, ok := grid.(*line.SparseGrid); !ok {
+		t.Fatalf("expected sparse grid for huge bounding box")
+	}
+
+	grid.ApplyLine(line.NewLine(500000000, 499999990, 500000000, 500000000))
+	grid.ApplyLine(line.NewLine(499999995, 499999995, 500000000, 499999995))
+
+	if grid.CountIntersections() != 1 {
+		t.Errorf("expected 1 intersection, actual %d", grid.CountIntersections())
+	}
+}
+
+func TestMakeCoverageGridExtremeBounds(t *testing.T) {
+	bounds := [][2]line.Point{
+		{{X: math.MinInt64, Y: 0}, {X: math.MaxInt64, Y: 0}},
+		{{X: 0, Y: math.MinInt64}, {X: 0, Y: math.MaxInt64}},
+		{{X: -1, Y: -1}, {X: math.MaxInt64, Y: math.MaxInt64}},
+		{{X: 0, Y: 0}, {X: line.DenseGridMaxCells, Y: 0}},
+	}
+
+	for _, b := range bounds {
+		if _, ok := line.MakeCoverageGrid(b[0], b[1]).(*line.SparseGrid); !ok {
+			t.Errorf("expected sparse grid for bounding box %v", b)
+		}
+	}
+
+	if cells, ok := line.BoxCells(line.Point{X: 1, Y: 1}, line.Point{X: 4096, Y: 4096}, line.DenseGridMaxCells); !ok ||
+		cells != line.DenseGridMaxCells {
+		t.Errorf("expected %d cells, actual %d (%t)", line.DenseGridMaxCells, cells, ok)
+	}
+}
+
+func TestSparseGridLongLines(t *testing.T) {
+	// Lines this long would need hundreds of millions of cells if every covered cell was stored.
+	grid := line.NewSparseGrid()
+	grid.ApplyLine(line.NewLine(0, 0, 300000000, 300000000))
+	grid.ApplyLine(line.NewLine(0, 300000000, 300000000, 0))
+	grid.ApplyLine(line.NewLine(100000000, 100000000, 200000000, 200000000))
+	grid.ApplyLine(line.NewLine(250000000, -5, 250000000, 400000000))
+
+	// Overlap of the first and third line (100000001 cells) contains the crossing of the diagonals. The vertical line
+	// crosses both diagonals outside of it.
+	if expected := 100000001 + 2; grid.CountIntersections() != expected {
+		t.Errorf("expected %d intersections, actual %d", expected, grid.CountIntersections())
+	}
+
+	// Runs spanning more than the range of FindIntersections are counted cell by cell.
+	far := line.NewSparseGrid()
+	far.ApplyLine(line.NewLine(math.MinInt64/2, 0, math.MinInt64/2+10, 0))
+	far.ApplyLine(line.NewLine(math.MaxInt64/2, 0, math.MaxInt64/2, 10))
+	far.ApplyLine(line.NewLine(math.MinInt64/2+5, -3, math.MinInt64/2+5, 3))
+
+	if far.CountIntersections() != 1 {
+		t.Errorf("expected 1 intersection, actual %d", far.CountIntersections())
+	}
+}
+
+func TestSparseGridMatchesDense(t *testing.T) {
+	rng := rand.New(rand.NewSource(32))
+	rasterizers := map[string]line.Rasterizer{"bresenham": line.Bresenham, "supercover": line.Supercover}
+
+	for round := 0; round < 30; round++ {
+		var lines []*line.Line
+
+		// Arbitrary slopes are compressed into many short runs.
+		for i := 0; i < 30; i++ {
+			lines = append(lines, line.NewLine(rng.Intn(25), rng.Intn(25), rng.Intn(25), rng.Intn(25)))
+		}
+
+		for name, rasterize := range rasterizers {
+			dense := line.MakeOffsetGrid(line.Point{X: -1, Y: -1}, line.Point{X: 25, Y: 25})
+			sparse := line.NewSparseGrid()
+
+			for _, l := range lines {
+				dense.ApplyLineWith(l, rasterize)
+				sparse.ApplyLineWith(l, rasterize)
+			}
+
+			if dense.CountIntersections() != sparse.CountIntersections() {
+				t.Errorf("round %d %s: dense grid counted %d intersections, sparse %d", round, name,
+					dense.CountIntersections(), sparse.CountIntersections())
+			}
+		}
+	}
+}
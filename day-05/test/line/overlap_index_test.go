@@ -0,0 +1,48 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-5/internal/line"
+)
+
+func TestOverlapIndexExample(t *testing.T) {
+	index := line.NewOverlapIndex(exampleLines(), line.Bresenham)
+
+	if index.CountIntersections() != 12 {
+		t.Errorf("expected 12 intersections, actual %d", index.CountIntersections())
+	}
+
+	expectedHot := []line.CellCoverage{
+		{X: 4, Y: 4, Coverage: 3, Lines: []int{1, 2, 8}},
+		{X: 6, Y: 4, Coverage: 3, Lines: []int{2, 5, 9}},
+	}
+
+	if hot := index.HotPoints(2); !reflect.DeepEqual(hot, expectedHot) {
+		t.Errorf("expected hot points %+v, actual %+v", expectedHot, hot)
+	}
+
+	if len(index.HotPoints(0)) != 12 || len(index.CoveredAtLeast(3)) != 2 || len(index.CoveredAtLeast(4)) != 0 {
+		t.Errorf("unexpected number of covered cells")
+	}
+
+	if lines := index.LinesAt(line.Point{X: 0, Y: 9}); !reflect.DeepEqual(lines, []int{0, 6}) {
+		t.Errorf("expected lines [0 6] at 0,9, actual %v", lines)
+	}
+
+	if lines := index.LinesAt(line.Point{X: 9, Y: 9}); len(lines) != 0 || lines == nil {
+		t.Errorf("expected empty lines at 9,9, actual %v", lines)
+	}
+
+	overlaps := index.LineOverlaps()
+
+	if overlaps[3] != (line.LineOverlap{Line: 3, Cells: 2, OverlapCells: 1}) {
+		t.Errorf("unexpected overlap of line 3 (%+v)", overlaps[3])
+	}
+
+	// Line 6 lies entirely on line 0.
+	if overlaps[6] != (line.LineOverlap{Line: 6, Cells: 3, OverlapCells: 3}) {
+		t.Errorf("unexpected overlap of line 6 (%+v)", overlaps[6])
+	}
+}
@@ -0,0 +1,97 @@
+package test
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-5/internal/line"
+)
+
+// randomLines generates horizontal, vertical and diagonal lines inside [low, low + size).
+func randomLines(rng *rand.Rand, count int, low int, size int, maxLength int) []*line.Line {
+	directions := [][2]int{{1, 0}, {0, 1}, {1, 1}, {1, -1}}
+	lines := make([]*line.Line, count)
+
+	for i := range lines {
+		d := directions[rng.Intn(len(directions))]
+		length := rng.Intn(maxLength)
+		x := low + rng.Intn(size-length)
+		y := low + rng.Intn(size-length)
+
+		if d[1] < 0 {
+			y += length
+		}
+
+		lines[i] = line.NewLine(x, y, x+d[0]*length, y+d[1]*length)
+	}
+
+	return lines
+}
+
+func TestApplyLinesParallelMatchesSerial(t *testing.T) {
+	rng := rand.New(rand.NewSource(17))
+	lines := append(randomLines(rng, 20000, -50, 300, 120), exampleLines()...)
+	low, high := line.FindBounds(lines)
+
+	for _, rasterizer := range []line.Rasterizer{line.Bresenham, line.Supercover} {
+		serial := line.MakeOffsetGrid(low, high)
+
+		for _, l := range lines {
+			serial.ApplyLineWith(l, rasterizer)
+		}
+
+		for _, workers := range []int{0, 1, 3, 8, 1000} {
+			parallel := line.MakeOffsetGrid(low, high)
+			parallel.ApplyLinesParallel(lines, rasterizer, workers)
+
+			if !reflect.DeepEqual(parallel.Cells, serial.Cells) {
+				t.Errorf("workers %d: parallel grid differs from serial grid", workers)
+			}
+		}
+	}
+
+	grid := line.MakeGrid(10, 10)
+	grid.ApplyLinesParallel(exampleLines(), line.Bresenham, 4)
+
+	if grid.CountIntersections() != 12 {
+		t.Errorf("expected 12 intersections, actual %d", grid.CountIntersections())
+	}
+}
+
+var benchmarkLines []*line.Line
+
+func benchmarkInput() []*line.Line {
+	if benchmarkLines == nil {
+		benchmarkLines = randomLines(rand.New(rand.NewSource(1)), 2000000, 0, 1000, 60)
+	}
+
+	return benchmarkLines
+}
+
+func BenchmarkApplyLinesSerial(b *testing.B) {
+	lines := benchmarkInput()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		grid := line.MakeGrid(1000, 1000)
+
+		for _, l := range lines {
+			grid.ApplyLine(l)
+		}
+	}
+}
+
+func BenchmarkApplyLinesParallel(b *testing.B) {
+	lines := benchmarkInput()
+
+	for _, workers := range []int{2, 4, 8, 0} {
+		b.Run(fmt.Sprintf("workers-%d", workers), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				grid := line.MakeGrid(1000, 1000)
+				grid.ApplyLinesParallel(lines, line.Bresenham, workers)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-5/internal/line"
+)
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+
+	return a
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
+
+func sign(a int) int {
+	if a < 0 {
+		return -1
+	} else if a > 0 {
+		return 1
+	}
+
+	return 0
+}
+
+// floorDiv divides rounding towards negative infinity (b > 0).
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+
+	return q
+}
+
+func rasterize(l *line.Line, rasterizer line.Rasterizer) map[line.Point]int {
+	cells := make(map[line.Point]int)
+	rasterizer(l, func(p line.Point) { cells[p]++ })
+
+	return cells
+}
+
+// bruteForceBresenham checks every lattice point of the bounding box: point belongs to the line if it is nearest (ties
+// rounded up) to the exact line at its major axis coordinate.
+func bruteForceBresenham(l *line.Line) map[line.Point]int {
+	cells := make(map[line.Point]int)
+	dx, dy := l.B.X-l.A.X, l.B.Y-l.A.Y
+
+	for x := minInt(l.A.X, l.B.X); x <= maxInt(l.A.X, l.B.X); x++ {
+		for y := minInt(l.A.Y, l.B.Y); y <= maxInt(l.A.Y, l.B.Y); y++ {
+			var on bool
+
+			if dx == 0 && dy == 0 {
+				on = true
+			} else if abs(dx) >= abs(dy) {
+				// y == round_half_up(A.Y + (x - A.X) * dy / dx)
+				on = y == l.A.Y+floorDiv(2*(x-l.A.X)*dy*sign(dx)+abs(dx), 2*abs(dx))
+			} else {
+				on = x == l.A.X+floorDiv(2*(y-l.A.Y)*dx*sign(dy)+abs(dy), 2*abs(dy))
+			}
+
+			if on {
+				cells[line.Point{X: x, Y: y}] = 1
+			}
+		}
+	}
+
+	return cells
+}
+
+// bruteForceSupercover checks every cell around the bounding box: cell belongs to the line if its closed square
+// intersects the line. Coordinates are doubled so that square corners lie on integers.
+func bruteForceSupercover(l *line.Line) map[line.Point]int {
+	cells := make(map[line.Point]int)
+	ax, ay, bx, by := 2*l.A.X, 2*l.A.Y, 2*l.B.X, 2*l.B.Y
+
+	for x := minInt(l.A.X, l.B.X) - 1; x <= maxInt(l.A.X, l.B.X)+1; x++ {
+		for y := minInt(l.A.Y, l.B.Y) - 1; y <= maxInt(l.A.Y, l.B.Y)+1; y++ {
+			minX, maxX, minY, maxY := 2*x-1, 2*x+1, 2*y-1, 2*y+1
+
+			// Separating axes x and y.
+			if maxInt(ax, bx) < minX || minInt(ax, bx) > maxX || maxInt(ay, by) < minY || minInt(ay, by) > maxY {
+				continue
+			}
+
+			// Separating axis perpendicular to the line: all corners strictly on the same side.
+			positive, negative := false, false
+
+			for _, corner := range [4][2]int{{minX, minY}, {minX, maxY}, {maxX, minY}, {maxX, maxY}} {
+				cross := (bx-ax)*(corner[1]-ay) - (by-ay)*(corner[0]-ax)
+				positive = positive || cross >= 0
+				negative = negative || cross <= 0
+			}
+
+			if positive && negative {
+				cells[line.Point{X: x, Y: y}] = 1
+			}
+		}
+	}
+
+	return cells
+}
+
+func compareCells(t *testing.T, name string, l *line.Line, actual, expected map[line.Point]int) {
+	for p, count := range actual {
+		if count != 1 {
+			t.Errorf("%s %+v: cell %+v visited %d times", name, *l, p, count)
+		}
+
+		if expected[p] == 0 {
+			t.Errorf("%s %+v: unexpected cell %+v", name, *l, p)
+		}
+	}
+
+	for p := range expected {
+		if actual[p] == 0 {
+			t.Errorf("%s %+v: missing cell %+v", name, *l, p)
+		}
+	}
+}
+
+func TestRasterizersAgainstBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(5))
+	lines := exampleLines()
+
+	for i := 0; i < 500; i++ {
+		lines = append(lines, line.NewLine(rng.Intn(41)-20, rng.Intn(41)-20, rng.Intn(41)-20, rng.Intn(41)-20))
+	}
+
+	for _, l := range lines {
+		reversed := line.NewLine(l.B.X, l.B.Y, l.A.X, l.A.Y)
+		expectedBresenham := bruteForceBresenham(l)
+		expectedSupercover := bruteForceSupercover(l)
+
+		compareCells(t, "bresenham", l, rasterize(l, line.Bresenham), expectedBresenham)
+		compareCells(t, "bresenham reversed", l, rasterize(reversed, line.Bresenham), expectedBresenham)
+		compareCells(t, "supercover", l, rasterize(l, line.Supercover), expectedSupercover)
+		compareCells(t, "supercover reversed", l, rasterize(reversed, line.Supercover), expectedSupercover)
+
+		// Bresenham always picks cells that the line passes through.
+		for p := range expectedBresenham {
+			if expectedSupercover[p] == 0 {
+				t.Errorf("bresenham cell %+v of %+v is not in supercover", p, *l)
+			}
+		}
+	}
+}
@@ -0,0 +1,231 @@
+package test
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-5/internal/line"
+)
+
+func rat(v int) *big.Rat {
+	return new(big.Rat).SetInt64(int64(v))
+}
+
+func ratKey(x, y *big.Rat) string {
+	return x.RatString() + "," + y.RatString()
+}
+
+// containsRat checks whether the rational point lies on the line.
+func containsRat(l *line.Line, x, y *big.Rat) bool {
+	// Cross product of (B - A) and (P - A) must be zero.
+	px := new(big.Rat).Sub(x, rat(l.A.X))
+	py := new(big.Rat).Sub(y, rat(l.A.Y))
+	cross := new(big.Rat).Sub(new(big.Rat).Mul(rat(l.B.X-l.A.X), py), new(big.Rat).Mul(rat(l.B.Y-l.A.Y), px))
+
+	if cross.Sign() != 0 {
+		return false
+	}
+
+	return x.Cmp(rat(minInt(l.A.X, l.B.X))) >= 0 && x.Cmp(rat(maxInt(l.A.X, l.B.X))) <= 0 &&
+		y.Cmp(rat(minInt(l.A.Y, l.B.Y))) >= 0 && y.Cmp(rat(maxInt(l.A.Y, l.B.Y))) <= 0
+}
+
+func isPoint(l *line.Line) bool {
+	return l.A == l.B
+}
+
+func parallel(l1, l2 *line.Line) bool {
+	return (l1.B.X-l1.A.X)*(l2.B.Y-l2.A.Y)-(l1.B.Y-l1.A.Y)*(l2.B.X-l2.A.X) == 0
+}
+
+// bruteForceCrossing computes the single intersection point of two lines that are not both non-degenerate and parallel.
+func bruteForceCrossing(l1, l2 *line.Line) (*big.Rat, *big.Rat, bool) {
+	if isPoint(l1) {
+		return rat(l1.A.X), rat(l1.A.Y), containsRat(l2, rat(l1.A.X), rat(l1.A.Y))
+	}
+
+	if isPoint(l2) {
+		return rat(l2.A.X), rat(l2.A.Y), containsRat(l1, rat(l2.A.X), rat(l2.A.Y))
+	}
+
+	rx, ry := l1.B.X-l1.A.X, l1.B.Y-l1.A.Y
+	sx, sy := l2.B.X-l2.A.X, l2.B.Y-l2.A.Y
+	qx, qy := l2.A.X-l1.A.X, l2.A.Y-l1.A.Y
+	t := big.NewRat(int64(qx*sy-qy*sx), int64(rx*sy-ry*sx))
+	x := new(big.Rat).Add(rat(l1.A.X), new(big.Rat).Mul(t, rat(rx)))
+	y := new(big.Rat).Add(rat(l1.A.Y), new(big.Rat).Mul(t, rat(ry)))
+
+	return x, y, containsRat(l1, x, y) && containsRat(l2, x, y)
+}
+
+// latticePoints enumerates lattice points lying exactly on the line.
+func latticePoints(l *line.Line) []line.Point {
+	var points []line.Point
+
+	for x := minInt(l.A.X, l.B.X); x <= maxInt(l.A.X, l.B.X); x++ {
+		for y := minInt(l.A.Y, l.B.Y); y <= maxInt(l.A.Y, l.B.Y); y++ {
+			if containsRat(l, rat(x), rat(y)) {
+				points = append(points, line.Point{X: x, Y: y})
+			}
+		}
+	}
+
+	return points
+}
+
+func checkAgainstBruteForce(t *testing.T, lines []*line.Line) {
+	res, err := line.FindIntersections(lines)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	found := make(map[string]map[int]bool)
+
+	for _, isect := range res.Intersections {
+		key := ratKey(isect.X, isect.Y)
+
+		if found[key] != nil {
+			t.Errorf("intersection %s reported twice", key)
+		}
+
+		found[key] = make(map[int]bool)
+		expectedLines := 0
+
+		for _, l := range lines {
+			if containsRat(l, isect.X, isect.Y) {
+				expectedLines++
+			}
+		}
+
+		if len(isect.Lines) != expectedLines || expectedLines < 2 {
+			t.Errorf("intersection %s lists lines %v, expected %d lines", key, isect.Lines, expectedLines)
+		}
+
+		for _, idx := range isect.Lines {
+			found[key][idx] = true
+
+			if !containsRat(lines[idx], isect.X, isect.Y) {
+				t.Errorf("intersection %s lists line %d that does not contain it", key, idx)
+			}
+		}
+	}
+
+	overlaps := make(map[[2]int]line.Overlap)
+	for _, o := range res.Overlaps {
+		overlaps[[2]int{minInt(o.Lines[0], o.Lines[1]), maxInt(o.Lines[0], o.Lines[1])}] = o
+	}
+
+	for i := range lines {
+		for j := i + 1; j < len(lines); j++ {
+			l1, l2 := lines[i], lines[j]
+
+			if !isPoint(l1) && !isPoint(l2) && parallel(l1, l2) {
+				o, reported := overlaps[[2]int{i, j}]
+				shared := 0
+
+				for _, p := range latticePoints(l1) {
+					if containsRat(l2, rat(p.X), rat(p.Y)) {
+						shared++
+					}
+				}
+
+				if reported != (shared > 0) {
+					t.Errorf("lines %d and %d share %d lattice points, overlap reported: %t", i, j, shared, reported)
+				} else if reported && len(latticePoints(&line.Line{A: o.A, B: o.B})) != shared {
+					t.Errorf("overlap %+v of lines %d and %d does not span %d lattice points", o, i, j, shared)
+				}
+
+				continue
+			}
+
+			x, y, ok := bruteForceCrossing(l1, l2)
+
+			if ok && (!found[ratKey(x, y)][i] || !found[ratKey(x, y)][j]) {
+				t.Errorf("missing intersection of lines %d %+v and %d %+v at %s", i, *l1, j, *l2, ratKey(x, y))
+			}
+		}
+	}
+
+	coverage := make(map[line.Point]int)
+	for _, l := range lines {
+		for _, p := range latticePoints(l) {
+			coverage[p]++
+		}
+	}
+
+	expectedLattice := 0
+	for _, count := range coverage {
+		if count > 1 {
+			expectedLattice++
+		}
+	}
+
+	if res.LatticeOverlapCount != expectedLattice {
+		t.Errorf("expected %d lattice overlaps, actual %d", expectedLattice, res.LatticeOverlapCount)
+	}
+}
+
+func TestFindIntersectionsExample(t *testing.T) {
+	res, err := line.FindIntersections(exampleLines())
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	if res.LatticeOverlapCount != 12 {
+		t.Errorf("expected 12 lattice overlaps, actual %d", res.LatticeOverlapCount)
+	}
+
+	checkAgainstBruteForce(t, exampleLines())
+}
+
+func TestFindIntersectionsMatchesGrid(t *testing.T) {
+	rng := rand.New(rand.NewSource(11))
+	directions := [][2]int{{1, 0}, {0, 1}, {1, 1}, {1, -1}}
+
+	for round := 0; round < 20; round++ {
+		var lines []*line.Line
+		grid := line.MakeOffsetGrid(line.Point{X: 0, Y: -15}, line.Point{X: 45, Y: 45})
+
+		for i := 0; i < 60; i++ {
+			d := directions[rng.Intn(len(directions))]
+			length := rng.Intn(15)
+			x, y := rng.Intn(30), rng.Intn(30)
+			l := line.NewLine(x, y, x+d[0]*length, y+d[1]*length)
+			lines = append(lines, l)
+			grid.ApplyLine(l)
+		}
+
+		res, err := line.FindIntersections(lines)
+
+		if err != nil {
+			t.Fatalf("encountered error (%s)", err.Error())
+		}
+
+		if res.LatticeOverlapCount != grid.CountIntersections() {
+			t.Errorf("round %d: sweep counted %d lattice overlaps, grid %d", round, res.LatticeOverlapCount,
+				grid.CountIntersections())
+		}
+	}
+}
+
+func TestFindIntersectionsArbitrarySlopes(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+
+	for round := 0; round < 40; round++ {
+		var lines []*line.Line
+
+		for i := 0; i < 40; i++ {
+			// Small coordinate range produces many degenerate cases: shared endpoints, collinear overlaps, verticals
+			// and zero-length lines.
+			lines = append(lines, line.NewLine(rng.Intn(7), rng.Intn(7), rng.Intn(7), rng.Intn(7)))
+		}
+
+		t.Run(fmt.Sprintf("round-%d", round), func(t *testing.T) {
+			checkAgainstBruteForce(t, lines)
+		})
+	}
+}
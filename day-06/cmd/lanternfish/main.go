@@ -1,13 +1,14 @@
 package main
 
 import (
-	"encoding/csv"
 	"errors"
 	"flag"
 	"fmt"
 	"log"
+	"math/big"
 	"os"
-	"strconv"
+	"path/filepath"
+	"strings"
 
 	"github.com/PrimozLavric/advent-of-code-2021/day-6/internal/simulator"
 )
@@ -17,14 +18,196 @@ type application struct {
 	log *log.Logger
 }
 
-// parseFishFile reads fish internal timers from the provided file and generates reproduction histogram.
-func (app *application) parseFishFile(filePath string) ([simulator.MaxInternalTimer]int, error) {
+// parseFishFile reads fish internal timers from the provided file and generates reproduction histogram with
+// timerCount timer values.
+func (app *application) parseFishFile(filePath string, timerCount int) ([]int, error) {
 	file, err := os.Open(filePath)
 
-	var timerHistogram [simulator.MaxInternalTimer]int
+	if err != nil {
+		return nil, err
+	}
+
+	// Defer close the file.
+	defer func() {
+		err = file.Close()
+
+		if err != nil {
+			app.log.Printf("Failed to close file: %s\n", filePath)
+		}
+	}()
+
+	return simulator.ParseTimers(file, timerCount)
+}
+
+// readCheckpoint restores the simulator from the checkpoint file.
+func (app *application) readCheckpoint(filePath string) (*simulator.ReproductionSimulator, error) {
+	file, err := os.Open(filePath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Defer close the file.
+	defer func() {
+		err = file.Close()
+
+		if err != nil {
+			app.log.Printf("Failed to close file: %s\n", filePath)
+		}
+	}()
+
+	return simulator.ReadCheckpoint(file)
+}
+
+// writeCheckpoint writes the simulator state to the checkpoint file.
+func (app *application) writeCheckpoint(filePath string, sim *simulator.ReproductionSimulator) error {
+	file, err := os.Create(filePath)
+
+	if err != nil {
+		return err
+	}
+
+	// Defer close the file.
+	defer func() {
+		err = file.Close()
+
+		if err != nil {
+			app.log.Printf("Failed to close file: %s\n", filePath)
+		}
+	}()
+
+	return sim.WriteCheckpoint(file)
+}
+
+// loadSpecies reads species parameters from the JSON config file.
+func (app *application) loadSpecies(filePath string) (simulator.Species, error) {
+	file, err := os.Open(filePath)
+
+	if err != nil {
+		return simulator.DefaultSpecies, err
+	}
+
+	// Defer close the file.
+	defer func() {
+		err = file.Close()
+
+		if err != nil {
+			app.log.Printf("Failed to close file: %s\n", filePath)
+		}
+	}()
+
+	return simulator.LoadSpecies(file)
+}
+
+// fastForward prints population numDays days after the current day of the simulator computed with matrix
+// exponentiation. Reported day is counted from day 0, so it includes the day of a resumed checkpoint.
+func (app *application) fastForward(sim *simulator.ReproductionSimulator, numDays uint64, modulusValue string) {
+	var modulus *big.Int
+
+	if modulusValue != "" {
+		var ok bool
+
+		if modulus, ok = new(big.Int).SetString(modulusValue, 10); !ok {
+			app.log.Fatalf("Modulus %s is not an integer.", modulusValue)
+		}
+	}
+
+	histogram, err := sim.FastForward(numDays, modulus)
+
+	if err != nil {
+		app.log.Fatalf("Encountered error during fast-forward (%s).", err.Error())
+	}
+
+	day := new(big.Int).Add(new(big.Int).SetUint64(numDays), big.NewInt(int64(sim.Day())))
+
+	if modulus != nil {
+		fmt.Printf("There is %s fish (mod %s) after %s days.\n", simulator.SumCounts(histogram, modulus), modulus, day)
+	} else {
+		fmt.Printf("There is %s fish after %s days.\n", simulator.SumCounts(histogram, nil), day)
+	}
+}
+
+// simulateStochastic prints mean and percentile bands of the population for every day.
+func (app *application) simulateStochastic(timerHistogram []int, numDays int, cfg simulator.StochasticConfig) {
+	stats, err := simulator.SimulateStochastic(timerHistogram, numDays, cfg)
+
+	if err != nil {
+		app.log.Fatalf("Encountered error during stochastic simulation (%s).", err.Error())
+	}
+
+	fmt.Printf("%5s %14s", "day", "mean")
+
+	for _, p := range cfg.Percentiles {
+		fmt.Printf(" %12s", fmt.Sprintf("p%g", p))
+	}
+
+	fmt.Println()
+
+	for _, day := range stats {
+		fmt.Printf("%5d %14.2f", day.Day, day.Mean)
+
+		for _, value := range day.Percentiles {
+			fmt.Printf(" %12.1f", value)
+		}
+
+		fmt.Println()
+	}
+}
+
+// simulateEcosystem loads the ecosystem definition and prints per-species counts for every day.
+func (app *application) simulateEcosystem(filePath string, numDays int) {
+	file, err := os.Open(filePath)
+
+	if err != nil {
+		app.log.Fatalf("Encountered error while opening ecosystem file (%s).", err.Error())
+	}
+
+	// Defer close the file.
+	defer func() {
+		err = file.Close()
+
+		if err != nil {
+			app.log.Printf("Failed to close file: %s\n", filePath)
+		}
+	}()
+
+	cfg, err := simulator.LoadEcosystem(file)
+
+	if err != nil {
+		app.log.Fatalf("Encountered error while loading ecosystem (%s).", err.Error())
+	}
+
+	eco, err := simulator.NewEcosystem(cfg)
 
 	if err != nil {
-		return timerHistogram, err
+		app.log.Fatalf("Encountered error while creating ecosystem (%s).", err.Error())
+	}
+
+	fmt.Printf("%5s", "day")
+
+	for _, name := range eco.Names() {
+		fmt.Printf(" %16s", name)
+	}
+
+	fmt.Println()
+
+	for day, counts := range eco.Simulate(numDays) {
+		fmt.Printf("%5d", day)
+
+		for _, count := range counts {
+			fmt.Printf(" %16.2f", count)
+		}
+
+		fmt.Println()
+	}
+}
+
+// writeTimeSeries writes the time series to a CSV or JSON file, depending on the extension.
+func (app *application) writeTimeSeries(filePath string, records []simulator.DayRecord) error {
+	file, err := os.Create(filePath)
+
+	if err != nil {
+		return err
 	}
 
 	// Defer close the file.
@@ -36,45 +219,235 @@ func (app *application) parseFishFile(filePath string) ([simulator.MaxInternalTi
 		}
 	}()
 
-	csvReader := csv.NewReader(file)
-	records, err := csvReader.ReadAll()
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".csv":
+		return simulator.WriteTimeSeriesCSV(file, records)
+	case ".json":
+		return simulator.WriteTimeSeriesJSON(file, records)
+	}
+
+	return errors.New(fmt.Sprintf("unknown time series extension '%s' (expected .csv or .json)", filepath.Ext(filePath)))
+}
+
+// analyze exports the time series and prints the requested growth analysis. Timer histogram is the state on startDay,
+// which is non-zero for a simulation resumed from a checkpoint, and reported days are counted from day 0.
+func (app *application) analyze(species simulator.Species, timerHistogram []int, startDay int, seriesFile string,
+	numDays int, growth bool, exceeds string) {
+	if seriesFile != "" {
+		records, err := simulator.TimeSeries(species, timerHistogram, numDays)
+
+		if err != nil {
+			app.log.Fatalf("Encountered error while computing time series (%s).", err.Error())
+		}
+
+		for i := range records {
+			records[i].Day += startDay
+		}
 
-	if len(records) != 1 {
-		return timerHistogram, errors.New("bad file format, expected single line with comma separated values")
+		if err := app.writeTimeSeries(seriesFile, records); err != nil {
+			app.log.Fatalf("Encountered error while writing time series (%s).", err.Error())
+		}
 	}
 
-	for i, record := range records[0] {
-		timerValue, err := strconv.Atoi(record)
+	if growth {
+		rate, err := simulator.GrowthRate(species)
 
 		if err != nil {
-			return timerHistogram, errors.New(fmt.Sprintf("could not convert record %d to integer (%s)", i+1, err.Error()))
+			app.log.Fatalf("Encountered error while computing growth rate (%s).", err.Error())
 		}
 
-		timerHistogram[timerValue]++
+		doublingTime, _ := simulator.DoublingTime(species)
+		fmt.Printf("Asymptotic daily growth rate: %.6f\n", rate)
+		fmt.Printf("Doubling time: %.2f days\n", doublingTime)
 	}
 
-	return timerHistogram, nil
+	if exceeds != "" {
+		n, ok := new(big.Int).SetString(exceeds, 10)
+
+		if !ok {
+			app.log.Fatalf("Population %s is not an integer.", exceeds)
+		}
+
+		day, err := simulator.FirstDayExceeding(species, timerHistogram, n)
+
+		if err != nil {
+			app.log.Fatalf("Encountered error while searching for the day (%s).", err.Error())
+		}
+
+		if day == 0 && startDay > 0 {
+			fmt.Printf("Population already exceeds %s on checkpoint day %d.\n", n, startDay)
+		} else {
+			fmt.Printf("Population first exceeds %s on day %d.\n", n, uint64(startDay)+day)
+		}
+	}
 }
 
 func main() {
 	var fishFile = flag.String("file", "input.txt", "Lanternfish internal timers file.")
+	var configFile = flag.String("config", "", "JSON species config file (empty uses the puzzle lanternfish).")
+	var cycle = flag.Int("cycle", simulator.DefaultSpecies.ReproductionCycle,
+		"Timer value a fish is reset to after reproducing.")
+	var newbornDelay = flag.Int("newborn-delay", simulator.DefaultSpecies.NewbornDelay,
+		"Extra days newborn fish need for the first cycle.")
+	var offspring = flag.Int("offspring", simulator.DefaultSpecies.OffspringPerCycle,
+		"Number of fish born to each reproducing fish.")
+	var mortality = flag.Float64("mortality", simulator.DefaultSpecies.Mortality, "Fraction of fish dying every day.")
+	var fastForwardDays = flag.Uint64("fast-forward", 0,
+		"Compute population this many days after the start or checkpoint with matrix exponentiation (0 disables).")
+	var modulus = flag.String("mod", "", "Compute fast-forwarded population modulo this number (empty computes it exactly).")
+	var stochastic = flag.Bool("stochastic", false, "Run individual-based Monte Carlo simulation.")
+	var days = flag.Int("days", 80, "Number of days of the stochastic simulation.")
+	var replicates = flag.Int("replicates", 100, "Number of stochastic simulation replicates.")
+	var seed = flag.Int64("seed", 1, "Seed of the stochastic simulation.")
+	var workers = flag.Int("workers", 0, "Number of stochastic simulation workers (0 uses all CPUs).")
+	var cycleDist = flag.String("cycle-dist", "",
+		"Reproduction cycle length distribution, e.g. const:7, uniform:6:8, normal:7:0.5 (empty follows the species).")
+	var newbornDist = flag.String("newborn-dist", "",
+		"Newborn delay distribution, e.g. const:2 (empty follows the species).")
+	var lifespanDist = flag.String("lifespan-dist", "",
+		"Lifespan distribution, e.g. geometric:0.01 or normal:60:10 (empty follows species mortality).")
+	var ecosystemFile = flag.String("ecosystem", "",
+		"JSON ecosystem definition; prints per-species counts for -days days (empty disables).")
+	var seriesFile = flag.String("series", "",
+		"Write per-day histograms and totals for -days days to this .csv or .json file (empty disables).")
+	var analyze = flag.Bool("analyze", false, "Print asymptotic growth rate and doubling time.")
+	var exceeds = flag.String("exceeds", "", "Print the first day the population exceeds this number (empty disables).")
+	var resumeFile = flag.String("resume", "",
+		"Resume the simulation from this JSON checkpoint instead of the timers file (empty disables).")
+	var checkpointFile = flag.String("checkpoint", "", "Write a JSON checkpoint after the simulation (empty disables).")
+	var until = flag.Int("until", 0, "Simulate until this day instead of reporting days 80 and 256 (0 disables).")
 	flag.Parse()
 
 	app := application{log: log.Default()}
 
-	timerHistogram, err := app.parseFishFile(*fishFile)
+	if *ecosystemFile != "" {
+		app.simulateEcosystem(*ecosystemFile, *days)
+		return
+	}
 
-	if err != nil {
-		app.log.Fatalf("Encountered error during lanternfish internal timers file parsing (%s).", err.Error())
+	species := simulator.DefaultSpecies
+
+	if *configFile != "" {
+		var err error
+
+		if species, err = app.loadSpecies(*configFile); err != nil {
+			app.log.Fatalf("Encountered error while loading species config (%s).", err.Error())
+		}
+	}
+
+	// Explicitly set flags override the config.
+	speciesFlags := false
+
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "cycle", "newborn-delay", "offspring", "mortality":
+			speciesFlags = true
+		}
+
+		switch f.Name {
+		case "cycle":
+			species.ReproductionCycle = *cycle
+		case "newborn-delay":
+			species.NewbornDelay = *newbornDelay
+		case "offspring":
+			species.OffspringPerCycle = *offspring
+		case "mortality":
+			species.Mortality = *mortality
+		}
+	})
+
+	if err := species.Validate(); err != nil {
+		app.log.Fatalf("Invalid species parameters (%s).", err.Error())
+	}
+
+	var sim *simulator.ReproductionSimulator
+	var err error
+
+	if *resumeFile != "" {
+		// Resumed simulation continues with the species stored in the checkpoint.
+		if *configFile != "" || speciesFlags {
+			app.log.Fatalf("Species config and parameter flags cannot be combined with -resume.")
+		}
+
+		if sim, err = app.readCheckpoint(*resumeFile); err != nil {
+			app.log.Fatalf("Encountered error while reading checkpoint (%s).", err.Error())
+		}
+
+		species = sim.Species()
+	} else {
+		timerHistogram, err := app.parseFishFile(*fishFile, species.TimerCount())
+
+		if err != nil {
+			app.log.Fatalf("Encountered error during lanternfish internal timers file parsing (%s).", err.Error())
+		}
+
+		if sim, err = simulator.NewSpeciesSimulator(species, timerHistogram); err != nil {
+			app.log.Fatalf("Encountered error while creating simulator (%s).", err.Error())
+		}
 	}
 
-	sim := simulator.NewReproductionSimulator(timerHistogram)
+	timerHistogram := sim.Histogram()
 
-	// Simulate 80 days.
-	sim.Simulate(80)
-	fmt.Printf("There is %d fish after 80 days.\n", sim.CountFish())
+	if *stochastic {
+		cfg := simulator.NewStochasticConfig(species)
+		cfg.Replicates = *replicates
+		cfg.Seed = *seed
+		cfg.Workers = *workers
 
-	// Simulate 176 more days to get to 256 days.
-	sim.Simulate(176)
-	fmt.Printf("There is %d fish after 256 days.\n", sim.CountFish())
+		specs := []string{*cycleDist, *newbornDist, *lifespanDist}
+		distributions := []*simulator.Distribution{&cfg.Cycle, &cfg.NewbornDelay, &cfg.Lifespan}
+
+		for i, spec := range specs {
+			if spec == "" {
+				continue
+			}
+
+			if *distributions[i], err = simulator.ParseDistribution(spec); err != nil {
+				app.log.Fatalf("Encountered error while parsing distribution (%s).", err.Error())
+			}
+		}
+
+		app.simulateStochastic(timerHistogram, *days, cfg)
+		return
+	}
+
+	if *seriesFile != "" || *analyze || *exceeds != "" {
+		app.analyze(species, timerHistogram, sim.Day(), *seriesFile, *days, *analyze, *exceeds)
+		return
+	}
+
+	if *fastForwardDays > 0 {
+		app.fastForward(sim, *fastForwardDays, *modulus)
+		return
+	}
+
+	reportDays := []int{80, 256}
+
+	if *until > 0 {
+		reportDays = []int{*until}
+	}
+
+	if *until > 0 && *until < sim.Day() {
+		app.log.Fatalf("Cannot simulate until day %d, simulation is already at day %d.", *until, sim.Day())
+	}
+
+	if last := reportDays[len(reportDays)-1]; last < sim.Day() {
+		app.log.Fatalf("Simulation is already at day %d, past the last reported day %d.", sim.Day(), last)
+	}
+
+	// Simulate up to each reported day, days that a resumed simulation already passed are skipped.
+	for _, day := range reportDays {
+		if day < sim.Day() {
+			continue
+		}
+
+		sim.Simulate(day - sim.Day())
+		fmt.Printf("There is %d fish after %d days.\n", sim.CountFish(), day)
+	}
+
+	if *checkpointFile != "" {
+		if err := app.writeCheckpoint(*checkpointFile, sim); err != nil {
+			app.log.Fatalf("Encountered error while writing checkpoint (%s).", err.Error())
+		}
+	}
 }
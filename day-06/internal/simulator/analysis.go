@@ -0,0 +1,217 @@
+package simulator
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+)
+
+// DayRecord holds the population of a single day.
+type DayRecord struct {
+	Day       int        `json:"day"`
+	Histogram []*big.Int `json:"histogram"`
+	Total     *big.Int   `json:"total"`
+}
+
+// TimeSeries returns the histogram and the total count at the start and after every day of numDays days. Species
+// without mortality are simulated with exact arbitrary precision counts.
+func TimeSeries(species Species, histogram []int, numDays int) ([]DayRecord, error) {
+	if numDays < 0 {
+		return nil, errors.New(fmt.Sprintf("invalid number of days %d", numDays))
+	}
+
+	sim, err := NewSpeciesSimulator(species, histogram)
+
+	if err != nil {
+		return nil, err
+	}
+
+	current := make([]*big.Int, species.TimerCount())
+	for i, count := range sim.Histogram() {
+		current[i] = big.NewInt(int64(count))
+	}
+
+	var transition Matrix
+
+	if species.Mortality == 0 {
+		transition, _ = TransitionMatrix(species)
+	}
+
+	records := make([]DayRecord, 0, numDays+1)
+
+	for day := 0; day <= numDays; day++ {
+		records = append(records, DayRecord{Day: day, Histogram: current, Total: SumCounts(current, nil)})
+
+		if transition != nil {
+			current = transition.Apply(current, nil)
+			continue
+		}
+
+		// Mortality rounds deaths, so the simulator is stepped instead.
+		sim.SimulateDay()
+		current = make([]*big.Int, species.TimerCount())
+
+		for i, count := range sim.Histogram() {
+			current[i] = big.NewInt(int64(count))
+		}
+	}
+
+	return records, nil
+}
+
+// WriteTimeSeriesCSV writes records as CSV with columns day, timer_0, ..., timer_n and total.
+func WriteTimeSeriesCSV(w io.Writer, records []DayRecord) error {
+	csvWriter := csv.NewWriter(w)
+
+	if len(records) > 0 {
+		header := []string{"day"}
+
+		for i := range records[0].Histogram {
+			header = append(header, fmt.Sprintf("timer_%d", i))
+		}
+
+		if err := csvWriter.Write(append(header, "total")); err != nil {
+			return err
+		}
+	}
+
+	for _, record := range records {
+		row := []string{strconv.Itoa(record.Day)}
+
+		for _, count := range record.Histogram {
+			row = append(row, count.String())
+		}
+
+		if err := csvWriter.Write(append(row, record.Total.String())); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// WriteTimeSeriesJSON writes records as a JSON array.
+func WriteTimeSeriesJSON(w io.Writer, records []DayRecord) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(records)
+}
+
+// GrowthRate returns the asymptotic daily growth factor of the population, which is the dominant eigenvalue of the
+// transition matrix. Without mortality it is the largest real root of x^(cycle + delay) = x^delay + offspring, where
+// cycle = ReproductionCycle + 1 and delay = NewbornDelay. Mortality scales it by (1 - Mortality).
+func GrowthRate(species Species) (float64, error) {
+	if err := species.Validate(); err != nil {
+		return 0, err
+	}
+
+	cycle := float64(species.ReproductionCycle + 1)
+	delay := float64(species.NewbornDelay)
+	offspring := float64(species.OffspringPerCycle)
+
+	f := func(x float64) float64 {
+		return math.Pow(x, cycle+delay) - math.Pow(x, delay) - offspring
+	}
+
+	// f(1) = -offspring <= 0, f(2 + offspring) > 0 and f is increasing for x >= 1.
+	low, high := 1.0, 2+offspring
+
+	for i := 0; i < 200; i++ {
+		mid := (low + high) / 2
+
+		if f(mid) > 0 {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+
+	return low * (1 - species.Mortality), nil
+}
+
+// DoublingTime returns the number of days the population needs to double at the asymptotic growth rate. It is
+// infinite if the population does not grow.
+func DoublingTime(species Species) (float64, error) {
+	rate, err := GrowthRate(species)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if rate <= 1 {
+		return math.Inf(1), nil
+	}
+
+	return math.Ln2 / math.Log(rate), nil
+}
+
+// FirstDayExceeding returns the first day on which the population is larger than n. The population of species without
+// mortality never shrinks, so the day is found by exponential and binary search over FastForward.
+func FirstDayExceeding(species Species, histogram []int, n *big.Int) (uint64, error) {
+	count := func(day uint64) (*big.Int, error) {
+		counts, err := FastForward(species, histogram, day, nil)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return SumCounts(counts, nil), nil
+	}
+
+	initial, err := count(0)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if initial.Cmp(n) > 0 {
+		return 0, nil
+	}
+
+	if initial.Sign() == 0 || species.OffspringPerCycle == 0 {
+		return 0, errors.New(fmt.Sprintf("population never exceeds %s", n.String()))
+	}
+
+	// Find a day on which the population exceeds n.
+	low, high := uint64(0), uint64(1)
+
+	for {
+		total, err := count(high)
+
+		if err != nil {
+			return 0, err
+		}
+
+		if total.Cmp(n) > 0 {
+			break
+		}
+
+		low, high = high, high*2
+	}
+
+	// Invariant: population on day low is at most n, on day high it exceeds n.
+	for high-low > 1 {
+		mid := low + (high-low)/2
+		total, err := count(mid)
+
+		if err != nil {
+			return 0, err
+		}
+
+		if total.Cmp(n) > 0 {
+			high = mid
+		} else {
+			low = mid
+		}
+	}
+
+	return high, nil
+}
@@ -0,0 +1,270 @@
+package simulator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// EcosystemSpecies is a species living in an ecosystem.
+type EcosystemSpecies struct {
+	Species
+	// Name identifies the species in interaction rules.
+	Name string `json:"name"`
+	// Timers internal timers of the initial fish.
+	Timers []int `json:"timers"`
+	// CarryingCapacity limits births logistically by factor (1 - N / CarryingCapacity). Zero means no limit.
+	CarryingCapacity float64 `json:"carrying_capacity"`
+}
+
+// Predation makes predators eat Rate * predators * prey fish of the prey species every day (at most all prey). Every
+// eaten fish gives birth to Conversion newborn predators.
+type Predation struct {
+	Predator   string  `json:"predator"`
+	Prey       string  `json:"prey"`
+	Rate       float64 `json:"rate"`
+	Conversion float64 `json:"conversion"`
+}
+
+// Competition makes species share a carrying capacity. Births of each member are limited by factor
+// (1 - total members / Capacity).
+type Competition struct {
+	Species  []string `json:"species"`
+	Capacity float64  `json:"capacity"`
+}
+
+// EcosystemConfig describes the species and their interactions.
+type EcosystemConfig struct {
+	Species     []EcosystemSpecies `json:"species"`
+	Predation   []Predation        `json:"predation"`
+	Competition []Competition      `json:"competition"`
+}
+
+// LoadEcosystem reads and validates an ecosystem config from JSON. Missing species parameters are taken from
+// DefaultSpecies.
+func LoadEcosystem(r io.Reader) (*EcosystemConfig, error) {
+	var raw struct {
+		Species     []json.RawMessage `json:"species"`
+		Predation   []Predation       `json:"predation"`
+		Competition []Competition     `json:"competition"`
+	}
+
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&raw); err != nil {
+		return nil, errors.New(fmt.Sprintf("bad ecosystem config (%s)", err.Error()))
+	}
+
+	cfg := EcosystemConfig{Predation: raw.Predation, Competition: raw.Competition}
+
+	for i, data := range raw.Species {
+		species := EcosystemSpecies{Species: DefaultSpecies}
+		speciesDecoder := json.NewDecoder(bytes.NewReader(data))
+		speciesDecoder.DisallowUnknownFields()
+
+		if err := speciesDecoder.Decode(&species); err != nil {
+			return nil, errors.New(fmt.Sprintf("bad species %d (%s)", i+1, err.Error()))
+		}
+
+		cfg.Species = append(cfg.Species, species)
+	}
+
+	return &cfg, cfg.Validate()
+}
+
+// Validate checks species parameters and that interactions refer to existing species.
+func (cfg *EcosystemConfig) Validate() error {
+	if len(cfg.Species) == 0 {
+		return errors.New("ecosystem has no species")
+	}
+
+	names := make(map[string]bool)
+
+	for _, species := range cfg.Species {
+		if species.Name == "" || names[species.Name] {
+			return errors.New(fmt.Sprintf("species name '%s' is empty or not unique", species.Name))
+		}
+
+		names[species.Name] = true
+
+		if err := species.Validate(); err != nil {
+			return errors.New(fmt.Sprintf("species %s: %s", species.Name, err.Error()))
+		}
+
+		if species.CarryingCapacity < 0 {
+			return errors.New(fmt.Sprintf("species %s: carrying capacity must not be negative", species.Name))
+		}
+
+		for i, timer := range species.Timers {
+			if timer < 0 || timer >= species.TimerCount() {
+				return errors.New(fmt.Sprintf("species %s: timer %d (%d) is not in range [0, %d]", species.Name, i+1,
+					timer, species.TimerCount()-1))
+			}
+		}
+	}
+
+	for i, rule := range cfg.Predation {
+		if !names[rule.Predator] || !names[rule.Prey] {
+			return errors.New(fmt.Sprintf("predation rule %d refers to unknown species", i+1))
+		}
+
+		if rule.Rate < 0 || rule.Conversion < 0 {
+			return errors.New(fmt.Sprintf("predation rule %d has negative rate or conversion", i+1))
+		}
+	}
+
+	for i, rule := range cfg.Competition {
+		for _, name := range rule.Species {
+			if !names[name] {
+				return errors.New(fmt.Sprintf("competition rule %d refers to unknown species %s", i+1, name))
+			}
+		}
+
+		if rule.Capacity <= 0 {
+			return errors.New(fmt.Sprintf("competition rule %d must have positive capacity", i+1))
+		}
+	}
+
+	return nil
+}
+
+// Ecosystem simulates several interacting species. Populations are continuous, so fractions of fish are kept.
+type Ecosystem struct {
+	cfg        *EcosystemConfig
+	index      map[string]int
+	histograms [][]float64
+	day        int
+}
+
+// NewEcosystem creates an ecosystem from a validated config.
+func NewEcosystem(cfg *EcosystemConfig) (*Ecosystem, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	eco := Ecosystem{cfg: cfg, index: make(map[string]int), histograms: make([][]float64, len(cfg.Species))}
+
+	for i, species := range cfg.Species {
+		eco.index[species.Name] = i
+		eco.histograms[i] = make([]float64, species.TimerCount())
+
+		for _, timer := range species.Timers {
+			eco.histograms[i][timer]++
+		}
+	}
+
+	return &eco, nil
+}
+
+// Names returns species names in config order.
+func (eco *Ecosystem) Names() []string {
+	names := make([]string, len(eco.cfg.Species))
+
+	for i, species := range eco.cfg.Species {
+		names[i] = species.Name
+	}
+
+	return names
+}
+
+// Day returns the number of simulated days.
+func (eco *Ecosystem) Day() int {
+	return eco.day
+}
+
+// Counts returns current populations of the species in config order.
+func (eco *Ecosystem) Counts() []float64 {
+	counts := make([]float64, len(eco.histograms))
+
+	for i, histogram := range eco.histograms {
+		for _, count := range histogram {
+			counts[i] += count
+		}
+	}
+
+	return counts
+}
+
+// SimulateDay simulates a single day. All interactions are computed from the populations at the start of the day.
+func (eco *Ecosystem) SimulateDay() {
+	counts := eco.Counts()
+
+	// Birth limits of logistic growth and competition.
+	birthFactors := make([]float64, len(counts))
+
+	for i, species := range eco.cfg.Species {
+		birthFactors[i] = 1
+
+		if species.CarryingCapacity > 0 {
+			birthFactors[i] *= math.Max(0, 1-counts[i]/species.CarryingCapacity)
+		}
+	}
+
+	for _, rule := range eco.cfg.Competition {
+		total := 0.0
+
+		for _, name := range rule.Species {
+			total += counts[eco.index[name]]
+		}
+
+		for _, name := range rule.Species {
+			birthFactors[eco.index[name]] *= math.Max(0, 1-total/rule.Capacity)
+		}
+	}
+
+	// Fraction of each species that survives predators and newborns of predators.
+	survival := make([]float64, len(counts))
+	predatorBirths := make([]float64, len(counts))
+
+	for i := range survival {
+		survival[i] = 1
+	}
+
+	for _, rule := range eco.cfg.Predation {
+		predator, prey := eco.index[rule.Predator], eco.index[rule.Prey]
+
+		if counts[prey] == 0 {
+			continue
+		}
+
+		eaten := math.Min(counts[prey]*survival[prey], rule.Rate*counts[predator]*counts[prey])
+		survival[prey] -= eaten / counts[prey]
+		predatorBirths[predator] += eaten * rule.Conversion
+	}
+
+	for i, species := range eco.cfg.Species {
+		histogram := eco.histograms[i]
+		reproducingFish := histogram[0]
+
+		for t := 1; t < len(histogram); t++ {
+			histogram[t-1] = histogram[t]
+		}
+
+		histogram[len(histogram)-1] = 0
+		histogram[species.ReproductionCycle] += reproducingFish
+		histogram[len(histogram)-1] += reproducingFish*float64(species.OffspringPerCycle)*birthFactors[i] +
+			predatorBirths[i]
+
+		for t := range histogram {
+			histogram[t] *= survival[i] * (1 - species.Mortality)
+		}
+	}
+
+	eco.day++
+}
+
+// Simulate simulates provided number of days and returns populations at the start and after every day.
+func (eco *Ecosystem) Simulate(numDays int) [][]float64 {
+	series := [][]float64{eco.Counts()}
+
+	for i := 0; i < numDays; i++ {
+		eco.SimulateDay()
+		series = append(series, eco.Counts())
+	}
+
+	return series
+}
@@ -0,0 +1,180 @@
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// Matrix is a square matrix of arbitrary precision integers.
+type Matrix [][]*big.Int
+
+// NewMatrix creates a zero matrix of size n x n.
+func NewMatrix(n int) Matrix {
+	m := make(Matrix, n)
+
+	for i := range m {
+		m[i] = make([]*big.Int, n)
+
+		for j := range m[i] {
+			m[i][j] = new(big.Int)
+		}
+	}
+
+	return m
+}
+
+// IdentityMatrix creates an identity matrix of size n x n.
+func IdentityMatrix(n int) Matrix {
+	m := NewMatrix(n)
+
+	for i := range m {
+		m[i][i].SetInt64(1)
+	}
+
+	return m
+}
+
+// reduce takes value modulo modulus if modulus is not nil.
+func reduce(value *big.Int, modulus *big.Int) *big.Int {
+	if modulus != nil {
+		value.Mod(value, modulus)
+	}
+
+	return value
+}
+
+// Mul multiplies matrices m and other (optionally modulo modulus).
+func (m Matrix) Mul(other Matrix, modulus *big.Int) Matrix {
+	res := NewMatrix(len(m))
+	product := new(big.Int)
+
+	for i := range m {
+		for k := range other {
+			if m[i][k].Sign() == 0 {
+				continue
+			}
+
+			for j := range other[k] {
+				res[i][j].Add(res[i][j], product.Mul(m[i][k], other[k][j]))
+			}
+		}
+
+		for j := range res[i] {
+			reduce(res[i][j], modulus)
+		}
+	}
+
+	return res
+}
+
+// Pow raises the matrix to the exponent by repeated squaring (optionally modulo modulus).
+func (m Matrix) Pow(exponent uint64, modulus *big.Int) Matrix {
+	res := IdentityMatrix(len(m))
+	square := m
+
+	for exponent > 0 {
+		if exponent&1 == 1 {
+			res = res.Mul(square, modulus)
+		}
+
+		exponent >>= 1
+
+		if exponent > 0 {
+			square = square.Mul(square, modulus)
+		}
+	}
+
+	return res
+}
+
+// Apply multiplies the matrix with the vector (optionally modulo modulus).
+func (m Matrix) Apply(vector []*big.Int, modulus *big.Int) []*big.Int {
+	res := make([]*big.Int, len(m))
+	product := new(big.Int)
+
+	for i := range m {
+		res[i] = new(big.Int)
+
+		for j, value := range vector {
+			res[i].Add(res[i], product.Mul(m[i][j], value))
+		}
+
+		reduce(res[i], modulus)
+	}
+
+	return res
+}
+
+// TransitionMatrix returns the matrix that maps the histogram of one day to the histogram of the next day. Species with
+// mortality have no exact linear transition.
+func TransitionMatrix(species Species) (Matrix, error) {
+	if err := species.Validate(); err != nil {
+		return nil, err
+	}
+
+	if species.Mortality != 0 {
+		return nil, errors.New("species with mortality can not be fast-forwarded")
+	}
+
+	n := species.TimerCount()
+	m := NewMatrix(n)
+
+	// Timers decrease by one.
+	for i := 0; i+1 < n; i++ {
+		m[i][i+1].SetInt64(1)
+	}
+
+	// Reproducing fish restart the cycle and give birth to newborns.
+	m[species.ReproductionCycle][0].Add(m[species.ReproductionCycle][0], big.NewInt(1))
+	m[n-1][0].Add(m[n-1][0], big.NewInt(int64(species.OffspringPerCycle)))
+
+	return m, nil
+}
+
+// FastForward computes the histogram after numDays days in O(log(numDays)) matrix multiplications. If modulus is not
+// nil, counts are computed modulo modulus (e.g. a large prime), otherwise they are exact.
+func FastForward(species Species, histogram []int, numDays uint64, modulus *big.Int) ([]*big.Int, error) {
+	if modulus != nil && modulus.Cmp(big.NewInt(1)) <= 0 {
+		return nil, errors.New(fmt.Sprintf("modulus must be greater than 1, got %s", modulus.String()))
+	}
+
+	if len(histogram) > species.TimerCount() {
+		return nil, errors.New(fmt.Sprintf("histogram has %d timer values, species only has %d", len(histogram),
+			species.TimerCount()))
+	}
+
+	m, err := TransitionMatrix(species)
+
+	if err != nil {
+		return nil, err
+	}
+
+	vector := make([]*big.Int, species.TimerCount())
+
+	for i := range vector {
+		vector[i] = new(big.Int)
+
+		if i < len(histogram) {
+			reduce(vector[i].SetInt64(int64(histogram[i])), modulus)
+		}
+	}
+
+	return m.Pow(numDays, modulus).Apply(vector, modulus), nil
+}
+
+// SumCounts sums the histogram counts (optionally modulo modulus).
+func SumCounts(histogram []*big.Int, modulus *big.Int) *big.Int {
+	sum := new(big.Int)
+
+	for _, count := range histogram {
+		sum.Add(sum, count)
+	}
+
+	return reduce(sum, modulus)
+}
+
+// FastForward computes the histogram numDays days after the current state of the simulator without changing it.
+func (sim *ReproductionSimulator) FastForward(numDays uint64, modulus *big.Int) ([]*big.Int, error) {
+	return FastForward(sim.species, sim.reproductionHistogram, numDays, modulus)
+}
@@ -0,0 +1,50 @@
+package simulator
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ParseTimers reads a single line of comma separated fish internal timers and returns their histogram with timerCount
+// timer values. Errors report the position of the offending record.
+func ParseTimers(r io.Reader, timerCount int) ([]int, error) {
+	csvReader := csv.NewReader(r)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+
+	records, err := csvReader.ReadAll()
+
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("bad file format (%s)", err.Error()))
+	}
+
+	if len(records) != 1 {
+		return nil, errors.New(fmt.Sprintf("bad file format, expected single line with comma separated values, got %d "+
+			"lines", len(records)))
+	}
+
+	histogram := make([]int, timerCount)
+
+	for i, record := range records[0] {
+		line, column := csvReader.FieldPos(i)
+		timerValue, err := strconv.Atoi(strings.TrimSpace(record))
+
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("record %d at line %d, column %d (%q) is not an integer", i+1, line,
+				column, record))
+		}
+
+		if timerValue < 0 || timerValue >= timerCount {
+			return nil, errors.New(fmt.Sprintf("record %d at line %d, column %d has timer %d outside range [0, %d]",
+				i+1, line, column, timerValue, timerCount-1))
+		}
+
+		histogram[timerValue]++
+	}
+
+	return histogram, nil
+}
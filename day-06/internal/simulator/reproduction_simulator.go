@@ -1,31 +1,85 @@
 package simulator
 
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// MaxInternalTimer is the number of internal timer values of the default species.
 const MaxInternalTimer = 9
+
+// ReproductionCycle is the timer value the default species is reset to after reproducing.
 const ReproductionCycle = 6
 
 // ReproductionSimulator used to simulate fish reproduction.
 type ReproductionSimulator struct {
-	reproductionHistogram [MaxInternalTimer]int
-	histogramOffset       uint8
+	species               Species
+	reproductionHistogram []int
+	day                   int
 }
 
-// NewReproductionSimulator creates and initializes ReproductionSimulator with provided reproduction histogram
+// NewReproductionSimulator creates and initializes ReproductionSimulator of the DefaultSpecies with provided
+// reproduction histogram.
 func NewReproductionSimulator(reproductionHistogram [MaxInternalTimer]int) *ReproductionSimulator {
-	sim := ReproductionSimulator{reproductionHistogram: reproductionHistogram, histogramOffset: 0}
+	sim, _ := NewSpeciesSimulator(DefaultSpecies, reproductionHistogram[:])
+
+	return sim
+}
+
+// NewSpeciesSimulator creates ReproductionSimulator of the provided species. Histogram holds the number of fish for
+// each internal timer value and may be shorter than species.TimerCount().
+func NewSpeciesSimulator(species Species, reproductionHistogram []int) (*ReproductionSimulator, error) {
+	if err := species.Validate(); err != nil {
+		return nil, err
+	}
+
+	if len(reproductionHistogram) > species.TimerCount() {
+		return nil, errors.New(fmt.Sprintf("histogram has %d timer values, species only has %d",
+			len(reproductionHistogram), species.TimerCount()))
+	}
+
+	sim := ReproductionSimulator{species: species, reproductionHistogram: make([]int, species.TimerCount())}
+	copy(sim.reproductionHistogram, reproductionHistogram)
 
-	return &sim
+	return &sim, nil
+}
+
+// Species returns parameters of the simulated species.
+func (sim *ReproductionSimulator) Species() Species {
+	return sim.species
+}
+
+// Day returns the number of simulated days.
+func (sim *ReproductionSimulator) Day() int {
+	return sim.day
+}
+
+// Histogram returns a copy of the current number of fish for each internal timer value.
+func (sim *ReproductionSimulator) Histogram() []int {
+	return append([]int(nil), sim.reproductionHistogram...)
 }
 
 // SimulateDay simulates singled day of reproduction.
 func (sim *ReproductionSimulator) SimulateDay() {
-	reproducingFish := sim.reproductionHistogram[0]
+	histogram := sim.reproductionHistogram
+	reproducingFish := histogram[0]
+
+	for i := 1; i < len(histogram); i++ {
+		histogram[i-1] = histogram[i]
+	}
+
+	histogram[len(histogram)-1] = 0
+	histogram[sim.species.ReproductionCycle] += reproducingFish
+	histogram[len(histogram)-1] += reproducingFish * sim.species.OffspringPerCycle
 
-	for i := 1; i < len(sim.reproductionHistogram); i++ {
-		sim.reproductionHistogram[i-1] = sim.reproductionHistogram[i]
+	if sim.species.Mortality > 0 {
+		for i, count := range histogram {
+			histogram[i] = count - int(math.Round(float64(count)*sim.species.Mortality))
+		}
 	}
 
-	sim.reproductionHistogram[ReproductionCycle] += reproducingFish
-	sim.reproductionHistogram[len(sim.reproductionHistogram)-1] = reproducingFish
+	sim.day++
 }
 
 // Simulate simulates provided number of days of reproduction.
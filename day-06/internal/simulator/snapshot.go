@@ -0,0 +1,69 @@
+package simulator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// SimulatorSnapshot holds the complete state of a ReproductionSimulator.
+type SimulatorSnapshot struct {
+	Species   Species `json:"species"`
+	Day       int     `json:"day"`
+	Histogram []int   `json:"histogram"`
+}
+
+// Snapshot captures the current state of the simulator.
+func (sim *ReproductionSimulator) Snapshot() SimulatorSnapshot {
+	return SimulatorSnapshot{Species: sim.species, Day: sim.day, Histogram: sim.Histogram()}
+}
+
+// RestoreSimulator creates a simulator from the snapshot.
+func RestoreSimulator(snapshot SimulatorSnapshot) (*ReproductionSimulator, error) {
+	if snapshot.Day < 0 {
+		return nil, errors.New(fmt.Sprintf("snapshot day %d is negative", snapshot.Day))
+	}
+
+	if len(snapshot.Histogram) != snapshot.Species.TimerCount() {
+		return nil, errors.New(fmt.Sprintf("snapshot histogram has %d timer values, expected %d",
+			len(snapshot.Histogram), snapshot.Species.TimerCount()))
+	}
+
+	for timer, count := range snapshot.Histogram {
+		if count < 0 {
+			return nil, errors.New(fmt.Sprintf("snapshot has negative count %d of timer %d", count, timer))
+		}
+	}
+
+	sim, err := NewSpeciesSimulator(snapshot.Species, snapshot.Histogram)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sim.day = snapshot.Day
+
+	return sim, nil
+}
+
+// WriteCheckpoint writes the simulator snapshot as JSON.
+func (sim *ReproductionSimulator) WriteCheckpoint(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(sim.Snapshot())
+}
+
+// ReadCheckpoint restores a simulator from a JSON snapshot.
+func ReadCheckpoint(r io.Reader) (*ReproductionSimulator, error) {
+	var snapshot SimulatorSnapshot
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&snapshot); err != nil {
+		return nil, errors.New(fmt.Sprintf("bad checkpoint (%s)", err.Error()))
+	}
+
+	return RestoreSimulator(snapshot)
+}
@@ -0,0 +1,68 @@
+package simulator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Species holds life cycle parameters of a fish species.
+type Species struct {
+	// ReproductionCycle is the timer value a fish is reset to after reproducing, so it reproduces every
+	// ReproductionCycle + 1 days.
+	ReproductionCycle int `json:"reproduction_cycle"`
+	// NewbornDelay is the number of extra days a newborn fish needs for its first cycle.
+	NewbornDelay int `json:"newborn_delay"`
+	// OffspringPerCycle is the number of fish born to each reproducing fish.
+	OffspringPerCycle int `json:"offspring_per_cycle"`
+	// Mortality is the fraction of fish that dies every day. Deaths are rounded to the nearest fish per timer value.
+	Mortality float64 `json:"mortality"`
+}
+
+// DefaultSpecies are the lanternfish from the puzzle.
+var DefaultSpecies = Species{
+	ReproductionCycle: ReproductionCycle,
+	NewbornDelay:      MaxInternalTimer - ReproductionCycle - 1,
+	OffspringPerCycle: 1,
+	Mortality:         0,
+}
+
+// TimerCount returns the number of distinct internal timer values (newborn timer value + 1).
+func (species Species) TimerCount() int {
+	return species.ReproductionCycle + species.NewbornDelay + 1
+}
+
+// Validate checks that the parameters describe a valid species.
+func (species Species) Validate() error {
+	if species.ReproductionCycle < 0 {
+		return errors.New(fmt.Sprintf("reproduction cycle must not be negative, got %d", species.ReproductionCycle))
+	}
+
+	if species.NewbornDelay < 0 {
+		return errors.New(fmt.Sprintf("newborn delay must not be negative, got %d", species.NewbornDelay))
+	}
+
+	if species.OffspringPerCycle < 0 {
+		return errors.New(fmt.Sprintf("offspring per cycle must not be negative, got %d", species.OffspringPerCycle))
+	}
+
+	if species.Mortality < 0 || species.Mortality > 1 {
+		return errors.New(fmt.Sprintf("mortality must be in range [0, 1], got %g", species.Mortality))
+	}
+
+	return nil
+}
+
+// LoadSpecies reads species parameters from JSON. Missing parameters are taken from DefaultSpecies.
+func LoadSpecies(r io.Reader) (Species, error) {
+	species := DefaultSpecies
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&species); err != nil {
+		return species, errors.New(fmt.Sprintf("bad species config (%s)", err.Error()))
+	}
+
+	return species, species.Validate()
+}
@@ -0,0 +1,358 @@
+package simulator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultMaxPopulation is the largest population a stochastic replicate may reach when no limit is configured.
+const DefaultMaxPopulation = 10000000
+
+// Distribution draws random integers.
+type Distribution interface {
+	// Sample draws a value using the provided random number generator.
+	Sample(rng *rand.Rand) int
+}
+
+// ConstantDistribution always returns Value.
+type ConstantDistribution struct {
+	Value int
+}
+
+// Sample returns Value.
+func (d ConstantDistribution) Sample(_ *rand.Rand) int {
+	return d.Value
+}
+
+// UniformDistribution draws uniformly from [Min, Max].
+type UniformDistribution struct {
+	Min int
+	Max int
+}
+
+// Sample draws uniformly from [Min, Max].
+func (d UniformDistribution) Sample(rng *rand.Rand) int {
+	return d.Min + rng.Intn(d.Max-d.Min+1)
+}
+
+// NormalDistribution draws from normal distribution and rounds to the nearest integer.
+type NormalDistribution struct {
+	Mean   float64
+	StdDev float64
+}
+
+// Sample draws from normal distribution and rounds to the nearest integer.
+func (d NormalDistribution) Sample(rng *rand.Rand) int {
+	return int(math.Round(rng.NormFloat64()*d.StdDev + d.Mean))
+}
+
+// GeometricDistribution draws the number of days until the first event that occurs each day with probability P.
+type GeometricDistribution struct {
+	P float64
+}
+
+// Sample draws the number of days (at least 1) until the first event.
+func (d GeometricDistribution) Sample(rng *rand.Rand) int {
+	if d.P >= 1 {
+		return 1
+	}
+
+	return 1 + int(math.Floor(math.Log(1-rng.Float64())/math.Log(1-d.P)))
+}
+
+// ParseDistribution parses a distribution specification: "const:V", "uniform:MIN:MAX", "normal:MEAN:STDDEV" or
+// "geometric:P".
+func ParseDistribution(spec string) (Distribution, error) {
+	parts := strings.Split(spec, ":")
+	params := make([]float64, len(parts)-1)
+
+	for i, part := range parts[1:] {
+		value, err := strconv.ParseFloat(part, 64)
+
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("bad parameter %d of distribution '%s' (%s)", i+1, spec, err.Error()))
+		}
+
+		params[i] = value
+	}
+
+	expectedParams := map[string]int{"const": 1, "uniform": 2, "normal": 2, "geometric": 1}
+
+	if expected, ok := expectedParams[parts[0]]; !ok {
+		return nil, errors.New(fmt.Sprintf("unknown distribution '%s'", parts[0]))
+	} else if expected != len(params) {
+		return nil, errors.New(fmt.Sprintf("distribution '%s' expects %d parameters, got %d", parts[0], expected,
+			len(params)))
+	}
+
+	switch parts[0] {
+	case "const":
+		return ConstantDistribution{Value: int(params[0])}, nil
+	case "uniform":
+		if params[0] > params[1] {
+			return nil, errors.New(fmt.Sprintf("uniform distribution minimum %g exceeds maximum %g", params[0], params[1]))
+		}
+
+		return UniformDistribution{Min: int(params[0]), Max: int(params[1])}, nil
+	case "normal":
+		if params[1] < 0 {
+			return nil, errors.New(fmt.Sprintf("normal distribution standard deviation %g is negative", params[1]))
+		}
+
+		return NormalDistribution{Mean: params[0], StdDev: params[1]}, nil
+	}
+
+	if params[0] <= 0 || params[0] > 1 {
+		return nil, errors.New(fmt.Sprintf("geometric distribution probability %g is not in range (0, 1]", params[0]))
+	}
+
+	return GeometricDistribution{P: params[0]}, nil
+}
+
+// StochasticConfig configures the individual-based simulation.
+type StochasticConfig struct {
+	// Cycle draws the length in days of each reproduction cycle. Lengths below 1 are treated as 1.
+	Cycle Distribution
+	// NewbornDelay draws extra days of a newborn's first cycle. Negative delays are treated as 0.
+	NewbornDelay Distribution
+	// Lifespan draws the day (counted from the start of the simulation or birth) on which a fish dies. Lifespans below 1
+	// are treated as 1. Fish never die if Lifespan is nil.
+	Lifespan Distribution
+	// OffspringPerCycle number of fish born to each reproducing fish.
+	OffspringPerCycle int
+	// Replicates number of simulated populations.
+	Replicates int
+	// Seed of the random number generator of the first replicate. Replicate i uses Seed + i.
+	Seed int64
+	// Workers number of goroutines, defaults to runtime.NumCPU() when zero.
+	Workers int
+	// MaxPopulation aborts the simulation when a replicate grows larger, defaults to DefaultMaxPopulation when zero.
+	MaxPopulation int
+	// Percentiles reported for each day, in range [0, 100].
+	Percentiles []float64
+}
+
+// NewStochasticConfig creates a config whose fish follow the species parameters. Mortality is turned into a geometric
+// lifespan.
+func NewStochasticConfig(species Species) StochasticConfig {
+	cfg := StochasticConfig{
+		Cycle:             ConstantDistribution{Value: species.ReproductionCycle + 1},
+		NewbornDelay:      ConstantDistribution{Value: species.NewbornDelay},
+		OffspringPerCycle: species.OffspringPerCycle,
+		Replicates:        100,
+		Percentiles:       []float64{5, 50, 95},
+	}
+
+	if species.Mortality > 0 {
+		cfg.Lifespan = GeometricDistribution{P: species.Mortality}
+	}
+
+	return cfg
+}
+
+// PopulationStats summarizes replicate populations of a single day.
+type PopulationStats struct {
+	Day         int
+	Mean        float64
+	Percentiles []float64
+}
+
+// fish is a single simulated individual.
+type fish struct {
+	timer    int
+	lifeLeft int
+}
+
+// stochasticReplicate simulates a single population.
+type stochasticReplicate struct {
+	cfg *StochasticConfig
+	rng *rand.Rand
+}
+
+// newFish creates a fish with the provided timer and a drawn lifespan.
+func (r *stochasticReplicate) newFish(timer int) fish {
+	f := fish{timer: timer, lifeLeft: -1}
+
+	if r.cfg.Lifespan != nil {
+		f.lifeLeft = r.cfg.Lifespan.Sample(r.rng)
+
+		if f.lifeLeft < 1 {
+			f.lifeLeft = 1
+		}
+	}
+
+	return f
+}
+
+// cycleTimer draws the timer value of a fish starting a new reproduction cycle.
+func (r *stochasticReplicate) cycleTimer() int {
+	cycle := r.cfg.Cycle.Sample(r.rng)
+
+	if cycle < 1 {
+		cycle = 1
+	}
+
+	return cycle - 1
+}
+
+// run simulates numDays days and returns the population at the start and after every day.
+func (r *stochasticReplicate) run(histogram []int, numDays int, maxPopulation int) ([]int, error) {
+	var population []fish
+
+	for timer, count := range histogram {
+		for i := 0; i < count; i++ {
+			population = append(population, r.newFish(timer))
+		}
+	}
+
+	counts := make([]int, numDays+1)
+	counts[0] = len(population)
+
+	for day := 1; day <= numDays; day++ {
+		alive := population[:0]
+		var newborns []fish
+
+		for _, f := range population {
+			if f.lifeLeft > 0 {
+				f.lifeLeft--
+			}
+
+			if f.lifeLeft == 0 {
+				continue
+			}
+
+			if f.timer == 0 {
+				f.timer = r.cycleTimer()
+
+				for i := 0; i < r.cfg.OffspringPerCycle; i++ {
+					delay := r.cfg.NewbornDelay.Sample(r.rng)
+
+					if delay < 0 {
+						delay = 0
+					}
+
+					newborns = append(newborns, r.newFish(r.cycleTimer()+delay))
+				}
+			} else {
+				f.timer--
+			}
+
+			alive = append(alive, f)
+		}
+
+		population = append(alive, newborns...)
+		counts[day] = len(population)
+
+		if len(population) > maxPopulation {
+			return nil, errors.New(fmt.Sprintf("population exceeded %d fish on day %d", maxPopulation, day))
+		}
+	}
+
+	return counts, nil
+}
+
+// percentile computes the p-th percentile of sorted values with linear interpolation.
+func percentile(sorted []int, p float64) float64 {
+	pos := p / 100 * float64(len(sorted)-1)
+	low := int(math.Floor(pos))
+
+	if low+1 >= len(sorted) {
+		return float64(sorted[len(sorted)-1])
+	}
+
+	return float64(sorted[low]) + (pos-float64(low))*float64(sorted[low+1]-sorted[low])
+}
+
+// SimulateStochastic runs cfg.Replicates individual-based simulations of numDays days in parallel, starting from the
+// timer histogram, and returns mean and percentiles of the population for each day (day 0 is the initial state).
+// Results only depend on the config, not on the number of workers.
+func SimulateStochastic(histogram []int, numDays int, cfg StochasticConfig) ([]PopulationStats, error) {
+	if cfg.Replicates <= 0 {
+		return nil, errors.New(fmt.Sprintf("invalid replicate count %d, must be positive", cfg.Replicates))
+	}
+
+	if numDays < 0 {
+		return nil, errors.New(fmt.Sprintf("invalid number of days %d", numDays))
+	}
+
+	if cfg.Cycle == nil || cfg.NewbornDelay == nil {
+		return nil, errors.New("cycle and newborn delay distributions are required")
+	}
+
+	for _, p := range cfg.Percentiles {
+		if p < 0 || p > 100 {
+			return nil, errors.New(fmt.Sprintf("percentile %g is not in range [0, 100]", p))
+		}
+	}
+
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+
+	maxPopulation := cfg.MaxPopulation
+	if maxPopulation <= 0 {
+		maxPopulation = DefaultMaxPopulation
+	}
+
+	replicateCounts := make([][]int, cfg.Replicates)
+	replicateErrors := make([]error, cfg.Replicates)
+	replicates := make(chan int)
+
+	var wg sync.WaitGroup
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for i := range replicates {
+				replicate := stochasticReplicate{cfg: &cfg, rng: rand.New(rand.NewSource(cfg.Seed + int64(i)))}
+				replicateCounts[i], replicateErrors[i] = replicate.run(histogram, numDays, maxPopulation)
+			}
+		}()
+	}
+
+	for i := 0; i < cfg.Replicates; i++ {
+		replicates <- i
+	}
+
+	close(replicates)
+	wg.Wait()
+
+	for i, err := range replicateErrors {
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("replicate %d failed (%s)", i, err.Error()))
+		}
+	}
+
+	stats := make([]PopulationStats, numDays+1)
+	dayCounts := make([]int, cfg.Replicates)
+
+	for day := range stats {
+		sum := 0.0
+
+		for i, counts := range replicateCounts {
+			dayCounts[i] = counts[day]
+			sum += float64(counts[day])
+		}
+
+		sort.Ints(dayCounts)
+
+		stats[day] = PopulationStats{Day: day, Mean: sum / float64(cfg.Replicates)}
+
+		for _, p := range cfg.Percentiles {
+			stats[day].Percentiles = append(stats[day].Percentiles, percentile(dayCounts, p))
+		}
+	}
+
+	return stats, nil
+}
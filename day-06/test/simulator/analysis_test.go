@@ -0,0 +1,135 @@
+package test
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-6/internal/simulator"
+)
+
+func TestTimeSeries(t *testing.T) {
+	records, err := simulator.TimeSeries(simulator.DefaultSpecies, []int{0, 1, 1, 2, 1}, 256)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	if len(records) != 257 || records[80].Total.Int64() != 5934 || records[256].Total.Int64() != 26984457539 {
+		t.Errorf("unexpected time series totals")
+	}
+
+	var buf bytes.Buffer
+
+	if err := simulator.WriteTimeSeriesCSV(&buf, records[:2]); err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	expectedCSV := "day,timer_0,timer_1,timer_2,timer_3,timer_4,timer_5,timer_6,timer_7,timer_8,total\n" +
+		"0,0,1,1,2,1,0,0,0,0,5\n" +
+		"1,1,1,2,1,0,0,0,0,0,5\n"
+
+	if buf.String() != expectedCSV {
+		t.Errorf("expected CSV:\n%s\nactual:\n%s", expectedCSV, buf.String())
+	}
+
+	buf.Reset()
+
+	if err := simulator.WriteTimeSeriesJSON(&buf, records[18:19]); err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	var decoded []struct {
+		Day   int
+		Total int
+	}
+
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil || decoded[0].Day != 18 || decoded[0].Total != 26 {
+		t.Errorf("unexpected JSON time series (%s)", buf.String())
+	}
+
+	// Species with mortality go through the rounding simulator.
+	mortal := simulator.DefaultSpecies
+	mortal.Mortality = 0.5
+	records, _ = simulator.TimeSeries(mortal, []int{0, 0, 0, 0, 0, 0, 0, 0, 100}, 3)
+
+	if records[3].Total.Int64() != 12 {
+		t.Errorf("expected 12 fishes, actual %s", records[3].Total)
+	}
+}
+
+func TestGrowthRate(t *testing.T) {
+	species := []simulator.Species{
+		simulator.DefaultSpecies,
+		{ReproductionCycle: 3, NewbornDelay: 0, OffspringPerCycle: 2},
+		{ReproductionCycle: 10, NewbornDelay: 5, OffspringPerCycle: 3},
+	}
+
+	for _, s := range species {
+		rate, err := simulator.GrowthRate(s)
+
+		if err != nil {
+			t.Fatalf("encountered error (%s)", err.Error())
+		}
+
+		// Daily growth averaged over a long window converges to the dominant eigenvalue, even when the population
+		// oscillates.
+		day1, _ := simulator.FastForward(s, []int{1}, 2000, nil)
+		day2, _ := simulator.FastForward(s, []int{1}, 4000, nil)
+		growth, _ := new(big.Rat).SetFrac(simulator.SumCounts(day2, nil), simulator.SumCounts(day1, nil)).Float64()
+		ratio := math.Pow(growth, 1.0/2000)
+
+		if math.Abs(rate-ratio) > 1e-4 {
+			t.Errorf("species %+v: expected growth rate %g, actual %g", s, ratio, rate)
+		}
+	}
+
+	doubling, _ := simulator.DoublingTime(simulator.DefaultSpecies)
+
+	if math.Abs(doubling-7.96) > 0.01 {
+		t.Errorf("expected doubling time 7.96, actual %g", doubling)
+	}
+
+	stable := simulator.Species{ReproductionCycle: 6, NewbornDelay: 2}
+
+	if doubling, _ := simulator.DoublingTime(stable); !math.IsInf(doubling, 1) {
+		t.Errorf("expected infinite doubling time, actual %g", doubling)
+	}
+}
+
+func TestFirstDayExceeding(t *testing.T) {
+	example := []int{0, 1, 1, 2, 1}
+	records, _ := simulator.TimeSeries(simulator.DefaultSpecies, example, 200)
+
+	for _, n := range []int64{0, 4, 5, 25, 26, 5933, 5934, 123456789} {
+		day, err := simulator.FirstDayExceeding(simulator.DefaultSpecies, example, big.NewInt(n))
+
+		if err != nil {
+			t.Fatalf("encountered error (%s)", err.Error())
+		}
+
+		expected := 0
+		for records[expected].Total.Int64() <= n {
+			expected++
+		}
+
+		if day != uint64(expected) {
+			t.Errorf("n = %d: expected day %d, actual %d", n, expected, day)
+		}
+	}
+
+	huge, _ := new(big.Int).SetString(strings.Repeat("9", 500), 10)
+
+	if _, err := simulator.FirstDayExceeding(simulator.DefaultSpecies, example, huge); err != nil {
+		t.Errorf("encountered error (%s)", err.Error())
+	}
+
+	stable := simulator.Species{ReproductionCycle: 6, NewbornDelay: 2}
+
+	if _, err := simulator.FirstDayExceeding(stable, example, big.NewInt(100)); err == nil {
+		t.Errorf("expected error for population that never grows, but none occurred")
+	}
+}
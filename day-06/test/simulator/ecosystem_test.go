@@ -0,0 +1,95 @@
+package test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-6/internal/simulator"
+)
+
+func loadEcosystem(t *testing.T, config string) *simulator.Ecosystem {
+	cfg, err := simulator.LoadEcosystem(strings.NewReader(config))
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	eco, err := simulator.NewEcosystem(cfg)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	return eco
+}
+
+func TestEcosystemWithoutInteractions(t *testing.T) {
+	eco := loadEcosystem(t, `{"species": [
+		{"name": "a", "timers": [3, 4, 3, 1, 2]},
+		{"name": "b", "timers": [3, 4, 3, 1, 2], "newborn_delay": 0, "offspring_per_cycle": 0}]}`)
+
+	series := eco.Simulate(80)
+
+	if series[18][0] != 26 || series[80][0] != 5934 {
+		t.Errorf("expected 26 and 5934 fishes of species a, actual %g and %g", series[18][0], series[80][0])
+	}
+
+	if series[80][1] != 5 || eco.Day() != 80 || eco.Names()[1] != "b" {
+		t.Errorf("expected 5 fishes of species b after 80 days, actual %g", series[80][1])
+	}
+}
+
+func TestEcosystemInteractions(t *testing.T) {
+	eco := loadEcosystem(t, `{"species": [{"name": "fish", "timers": [0, 1, 2, 3, 4, 5, 6], "carrying_capacity": 1000}]}`)
+
+	for day, counts := range eco.Simulate(400) {
+		if counts[0] > 1000+1e-6 {
+			t.Fatalf("day %d: population %g exceeds carrying capacity", day, counts[0])
+		}
+	}
+
+	if count := eco.Counts()[0]; count < 900 {
+		t.Errorf("expected population close to carrying capacity, actual %g", count)
+	}
+
+	// Predators without own reproduction only grow by eating prey.
+	eco = loadEcosystem(t, `{
+		"species": [
+			{"name": "prey", "timers": [1, 1, 1, 1], "offspring_per_cycle": 0},
+			{"name": "predator", "timers": [0, 0], "offspring_per_cycle": 0}],
+		"predation": [{"predator": "predator", "prey": "prey", "rate": 0.25, "conversion": 0.5}]}`)
+	eco.SimulateDay()
+
+	// 0.25 * 2 * 4 = 2 prey are eaten, giving birth to 1 predator.
+	if counts := eco.Counts(); math.Abs(counts[0]-2) > 1e-9 || math.Abs(counts[1]-3) > 1e-9 {
+		t.Errorf("expected 2 prey and 3 predators, actual %v", counts)
+	}
+
+	// Competing species share capacity.
+	eco = loadEcosystem(t, `{
+		"species": [{"name": "a", "timers": [0, 3, 5]}, {"name": "b", "timers": [1, 2]}],
+		"competition": [{"species": ["a", "b"], "capacity": 500}]}`)
+	eco.Simulate(300)
+
+	if counts := eco.Counts(); counts[0]+counts[1] > 500 {
+		t.Errorf("competing species exceed shared capacity (%v)", counts)
+	}
+}
+
+func TestLoadEcosystemErrors(t *testing.T) {
+	configs := []string{
+		`{"species": []}`,
+		`{"species": [{"name": "a"}, {"name": "a"}]}`,
+		`{"species": [{"name": "a", "timers": [9]}]}`,
+		`{"species": [{"name": "a", "speed": 3}]}`,
+		`{"species": [{"name": "a"}], "predation": [{"predator": "a", "prey": "b", "rate": 0.1}]}`,
+		`{"species": [{"name": "a"}], "competition": [{"species": ["a"], "capacity": 0}]}`,
+	}
+
+	for _, config := range configs {
+		if _, err := simulator.LoadEcosystem(strings.NewReader(config)); err == nil {
+			t.Errorf("expected error for config %s, but none occurred", config)
+		}
+	}
+}
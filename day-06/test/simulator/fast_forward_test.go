@@ -0,0 +1,81 @@
+package test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-6/internal/simulator"
+)
+
+func TestFastForwardMatchesSimulation(t *testing.T) {
+	example := []int{0, 1, 1, 2, 1}
+
+	histogram, err := simulator.FastForward(simulator.DefaultSpecies, example, 256, nil)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	if count := simulator.SumCounts(histogram, nil); count.Cmp(big.NewInt(26984457539)) != 0 {
+		t.Errorf("expected 26984457539 fishes, actual %s", count)
+	}
+
+	species := simulator.Species{ReproductionCycle: 3, NewbornDelay: 4, OffspringPerCycle: 2}
+	sim, _ := simulator.NewSpeciesSimulator(species, []int{1, 0, 3, 0, 2})
+
+	for day := uint64(0); day < 40; day++ {
+		histogram, err := sim.FastForward(day, nil)
+
+		if err != nil {
+			t.Fatalf("encountered error (%s)", err.Error())
+		}
+
+		expected, _ := simulator.NewSpeciesSimulator(species, []int{1, 0, 3, 0, 2})
+		expected.Simulate(int(day))
+
+		for i, count := range expected.Histogram() {
+			if histogram[i].Cmp(big.NewInt(int64(count))) != 0 {
+				t.Errorf("day %d: expected %d fish with timer %d, actual %s", day, count, i, histogram[i])
+			}
+		}
+	}
+
+	// Fast-forward does not change the simulator.
+	if sim.CountFish() != 6 {
+		t.Errorf("expected 6 fishes, actual %d", sim.CountFish())
+	}
+}
+
+func TestFastForwardModulo(t *testing.T) {
+	example := []int{0, 1, 1, 2, 1}
+	prime := big.NewInt(1000000007)
+
+	exact, _ := simulator.FastForward(simulator.DefaultSpecies, example, 5000, nil)
+	modular, err := simulator.FastForward(simulator.DefaultSpecies, example, 5000, prime)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	expected := new(big.Int).Mod(simulator.SumCounts(exact, nil), prime)
+
+	if count := simulator.SumCounts(modular, prime); count.Cmp(expected) != 0 {
+		t.Errorf("expected %s fishes modulo prime, actual %s", expected, count)
+	}
+
+	// A billion days only needs about 30 squarings.
+	if _, err := simulator.FastForward(simulator.DefaultSpecies, example, 1000000000, prime); err != nil {
+		t.Errorf("encountered error (%s)", err.Error())
+	}
+
+	if _, err := simulator.FastForward(simulator.DefaultSpecies, example, 10, big.NewInt(1)); err == nil {
+		t.Errorf("expected error for modulus 1, but none occurred")
+	}
+
+	mortal := simulator.DefaultSpecies
+	mortal.Mortality = 0.1
+
+	if _, err := simulator.FastForward(mortal, example, 10, nil); err == nil {
+		t.Errorf("expected error for species with mortality, but none occurred")
+	}
+}
@@ -0,0 +1,83 @@
+package test
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-6/internal/simulator"
+)
+
+func TestParseTimers(t *testing.T) {
+	histogram, err := simulator.ParseTimers(strings.NewReader("3,4,3,1, 2\n"), simulator.MaxInternalTimer)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	if !reflect.DeepEqual(histogram, []int{0, 1, 1, 2, 1, 0, 0, 0, 0}) {
+		t.Errorf("unexpected histogram %v", histogram)
+	}
+
+	testCases := map[string]string{
+		"3,4,9,1\n":  "record 3 at line 1, column 5 has timer 9",
+		"3,-1\n":     "record 2 at line 1, column 3 has timer -1",
+		"3,x,1\n":    "record 2 at line 1, column 3 (\"x\") is not an integer",
+		"3,,1\n":     "record 2",
+		"1,2\n3,4\n": "got 2 lines",
+		"":           "got 0 lines",
+	}
+
+	for input, expected := range testCases {
+		_, err := simulator.ParseTimers(strings.NewReader(input), simulator.MaxInternalTimer)
+
+		if err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("input %q: expected error containing '%s', actual %v", input, expected, err)
+		}
+	}
+}
+
+func TestCheckpointRestore(t *testing.T) {
+	sim := simulator.NewReproductionSimulator([simulator.MaxInternalTimer]int{0, 1, 1, 2, 1, 0, 0, 0, 0})
+	sim.Simulate(18)
+
+	var buf bytes.Buffer
+
+	if err := sim.WriteCheckpoint(&buf); err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	restored, err := simulator.ReadCheckpoint(&buf)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	if restored.Day() != 18 || restored.CountFish() != 26 || restored.Species() != simulator.DefaultSpecies {
+		t.Errorf("restored simulator differs (%+v)", restored.Snapshot())
+	}
+
+	restored.Simulate(62)
+
+	if restored.CountFish() != 5934 || restored.Day() != 80 {
+		t.Errorf("expected 5934 fishes on day 80, actual %d on day %d", restored.CountFish(), restored.Day())
+	}
+
+	checkpoints := []string{
+		`{"species": {"reproduction_cycle": 6, "newborn_delay": 2, "offspring_per_cycle": 1}, "day": -1,
+		  "histogram": [0, 0, 0, 0, 0, 0, 0, 0, 0]}`,
+		`{"species": {"reproduction_cycle": 6, "newborn_delay": 2, "offspring_per_cycle": 1}, "day": 1,
+		  "histogram": [0, 0, 0]}`,
+		`{"species": {"reproduction_cycle": 6, "newborn_delay": 2, "offspring_per_cycle": 1}, "day": 1,
+		  "histogram": [0, 0, 0, 0, -5, 0, 0, 0, 0]}`,
+		`{"species": {"reproduction_cycle": -6}, "day": 1, "histogram": []}`,
+		`{"day": 1, "elapsed": 3}`,
+	}
+
+	for _, checkpoint := range checkpoints {
+		if _, err := simulator.ReadCheckpoint(strings.NewReader(checkpoint)); err == nil {
+			t.Errorf("expected error for checkpoint %s, but none occurred", checkpoint)
+		}
+	}
+}
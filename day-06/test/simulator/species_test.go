@@ -0,0 +1,81 @@
+package test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-6/internal/simulator"
+)
+
+func TestDefaultSpeciesMatchesPuzzle(t *testing.T) {
+	sim, err := simulator.NewSpeciesSimulator(simulator.DefaultSpecies, []int{0, 1, 1, 2, 1})
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	sim.Simulate(80)
+
+	if sim.CountFish() != 5934 {
+		t.Errorf("expected 5934 fishes, actual %d", sim.CountFish())
+	}
+}
+
+func TestCustomSpecies(t *testing.T) {
+	// Every fish reproduces every 3 days with 2 offspring that need 1 extra day.
+	species := simulator.Species{ReproductionCycle: 2, NewbornDelay: 1, OffspringPerCycle: 2}
+	sim, err := simulator.NewSpeciesSimulator(species, []int{1})
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	expected := [][]int{
+		{0, 0, 1, 2},
+		{0, 1, 2, 0},
+		{1, 2, 0, 0},
+		{2, 0, 1, 2},
+	}
+
+	for day, histogram := range expected {
+		sim.SimulateDay()
+
+		if !reflect.DeepEqual(sim.Histogram(), histogram) {
+			t.Errorf("day %d: expected histogram %v, actual %v", day+1, histogram, sim.Histogram())
+		}
+	}
+
+	// Half of the fish die every day.
+	sim, _ = simulator.NewSpeciesSimulator(simulator.Species{ReproductionCycle: 6, NewbornDelay: 2, Mortality: 0.5},
+		[]int{0, 0, 0, 0, 0, 0, 0, 0, 100})
+	sim.Simulate(3)
+
+	if sim.CountFish() != 12 {
+		t.Errorf("expected 12 fishes, actual %d", sim.CountFish())
+	}
+}
+
+func TestLoadSpecies(t *testing.T) {
+	species, err := simulator.LoadSpecies(strings.NewReader(`{"reproduction_cycle": 4, "mortality": 0.1}`))
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	expected := simulator.Species{ReproductionCycle: 4, NewbornDelay: 2, OffspringPerCycle: 1, Mortality: 0.1}
+
+	if species != expected || species.TimerCount() != 7 {
+		t.Errorf("expected species %+v, actual %+v", expected, species)
+	}
+
+	for _, config := range []string{`{"mortality": 2}`, `{"newborn_delay": -1}`, `{"cycle": 3}`, `{`} {
+		if _, err := simulator.LoadSpecies(strings.NewReader(config)); err == nil {
+			t.Errorf("expected error for config %s, but none occurred", config)
+		}
+	}
+
+	if _, err := simulator.NewSpeciesSimulator(species, make([]int, 8)); err == nil {
+		t.Errorf("expected error for too long histogram, but none occurred")
+	}
+}
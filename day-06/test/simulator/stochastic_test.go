@@ -0,0 +1,95 @@
+package test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-6/internal/simulator"
+)
+
+func TestStochasticConstantMatchesDeterministic(t *testing.T) {
+	cfg := simulator.NewStochasticConfig(simulator.DefaultSpecies)
+	cfg.Replicates = 3
+
+	stats, err := simulator.SimulateStochastic([]int{0, 1, 1, 2, 1}, 80, cfg)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	for day, expected := range map[int]float64{0: 5, 18: 26, 80: 5934} {
+		if stats[day].Mean != expected || stats[day].Percentiles[0] != expected || stats[day].Percentiles[2] != expected {
+			t.Errorf("day %d: expected %g fishes, actual %+v", day, expected, stats[day])
+		}
+	}
+}
+
+func TestStochasticReplicates(t *testing.T) {
+	cfg := simulator.NewStochasticConfig(simulator.DefaultSpecies)
+	cfg.Cycle = simulator.UniformDistribution{Min: 5, Max: 9}
+	cfg.Lifespan = simulator.GeometricDistribution{P: 0.05}
+	cfg.Replicates = 40
+	cfg.Seed = 7
+	cfg.Workers = 1
+
+	serial, err := simulator.SimulateStochastic([]int{0, 1, 1, 2, 1}, 50, cfg)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	cfg.Workers = 4
+	parallel, _ := simulator.SimulateStochastic([]int{0, 1, 1, 2, 1}, 50, cfg)
+
+	if !reflect.DeepEqual(serial, parallel) {
+		t.Errorf("results depend on the number of workers")
+	}
+
+	for _, day := range serial {
+		p := day.Percentiles
+
+		if p[0] > p[1] || p[1] > p[2] {
+			t.Errorf("day %d: percentiles %v are not ordered", day.Day, p)
+		}
+	}
+
+	if serial[50].Percentiles[0] == serial[50].Percentiles[2] {
+		t.Errorf("expected spread between replicates, got %v", serial[50].Percentiles)
+	}
+
+	// Initial fish die on day 3, the two fish born on days 1 and 2 die on days 4 and 5.
+	cfg.Lifespan = simulator.ConstantDistribution{Value: 3}
+	stats, _ := simulator.SimulateStochastic([]int{1, 1, 1}, 5, cfg)
+
+	if stats[2].Mean != 5 || stats[3].Mean != 2 || stats[4].Mean != 1 || stats[5].Mean != 0 {
+		t.Errorf("unexpected population with constant lifespan (%+v)", stats)
+	}
+
+	cfg.Lifespan = nil
+	cfg.MaxPopulation = 100
+
+	if _, err := simulator.SimulateStochastic([]int{0, 1, 1, 2, 1}, 80, cfg); err == nil {
+		t.Errorf("expected population limit error, but none occurred")
+	}
+}
+
+func TestParseDistribution(t *testing.T) {
+	testCases := map[string]simulator.Distribution{
+		"const:7":        simulator.ConstantDistribution{Value: 7},
+		"uniform:6:8":    simulator.UniformDistribution{Min: 6, Max: 8},
+		"normal:7:0.5":   simulator.NormalDistribution{Mean: 7, StdDev: 0.5},
+		"geometric:0.01": simulator.GeometricDistribution{P: 0.01},
+	}
+
+	for spec, expected := range testCases {
+		if dist, err := simulator.ParseDistribution(spec); err != nil || dist != expected {
+			t.Errorf("%s: expected %+v, actual %+v (%v)", spec, expected, dist, err)
+		}
+	}
+
+	for _, spec := range []string{"poisson:3", "const", "uniform:8:6", "normal:1:-1", "geometric:0", "const:x"} {
+		if _, err := simulator.ParseDistribution(spec); err == nil {
+			t.Errorf("expected error for distribution %s, but none occurred", spec)
+		}
+	}
+}
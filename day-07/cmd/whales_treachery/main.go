@@ -9,6 +9,7 @@ import (
 	"os"
 	"strconv"
 
+	"github.com/PrimozLavric/advent-of-code-2021/day-7/internal/crab"
 	"github.com/PrimozLavric/advent-of-code-2021/day-7/internal/util"
 )
 
@@ -56,57 +57,293 @@ func (app *application) parseCrabPositionsFile(filePath string) ([]int, error) {
 	return crabPositions, nil
 }
 
-// computeFuelConsumptionPartOne computes minimal amount of fuel required to align crabs on the same position. Each
-// move costs 1 fuel unit.
-func computeFuelConsumptionPartOne(crabPositions []int) int {
-	// Optimal alignment position is median of crab positions.
-	medianPosition := util.ComputeMedian(crabPositions)
+// parseWeightsFile reads comma separated per-crab fuel weights.
+func (app *application) parseWeightsFile(filePath string) ([]float64, error) {
+	file, err := os.Open(filePath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Defer close the file.
+	defer func() {
+		err = file.Close()
+
+		if err != nil {
+			app.log.Printf("Failed to close file: %s\n", filePath)
+		}
+	}()
+
+	csvReader := csv.NewReader(file)
+	records, err := csvReader.ReadAll()
+
+	if len(records) != 1 {
+		return nil, errors.New("bad file format, expected single line with comma separated values")
+	}
+
+	var weights []float64
+
+	for i, record := range records[0] {
+		weight, err := strconv.ParseFloat(record, 64)
+
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("could not convert record %d to number (%s)", i+1, err.Error()))
+		}
+
+		weights = append(weights, weight)
+	}
+
+	return weights, nil
+}
+
+// computeFuelConsumption computes minimal amount of fuel required to align crabs on the same position with the
+// provided cost function.
+func (app *application) computeFuelConsumption(crabPositions []int, cost crab.CostFunction) crab.Alignment {
+	alignment, err := crab.Solve(crabPositions, cost)
+
+	if err != nil {
+		app.log.Fatalf("Encountered error while aligning crabs (%s).", err.Error())
+	}
+
+	return alignment
+}
+
+// printClusters prints optimal alignment of crabs at k positions.
+func (app *application) printClusters(crabPositions []int, cost crab.CostFunction, k int) {
+	clustering, err := crab.SolveClusters(crabPositions, cost, k)
+
+	if err != nil {
+		app.log.Fatalf("Encountered error while clustering crabs (%s).", err.Error())
+	}
 
-	usedFuel := 0
+	for _, cluster := range clustering.Clusters {
+		members := make([]int, len(cluster.Crabs))
 
-	for _, position := range crabPositions {
-		if position > medianPosition {
-			usedFuel += position - medianPosition
-		} else {
-			usedFuel += medianPosition - position
+		for i, crabIdx := range cluster.Crabs {
+			members[i] = crabPositions[crabIdx]
 		}
+
+		fmt.Printf("Position %d gathers %d crabs (median %d, mean %d) using %g fuel.\n", cluster.Position,
+			len(cluster.Crabs), util.ComputeMedian(members), util.ComputeMean(members), cluster.Fuel)
 	}
 
-	return usedFuel
+	fmt.Printf("Total fuel: %g\n", clustering.Fuel)
 }
 
-// computeFuelConsumptionPartOne computes minimal amount of fuel required to align crabs on the same position. Each
-// subsequent move is more costly. For example 4 moves of the same crab cost (1 + 2 + 3 + 4).
-func computeFuelConsumptionPartTwo(crabPositions []int) int {
-	// Optimal alignment position is mean of crab positions.
-	mean := util.ComputeMean(crabPositions)
+// alignOnPlane prints optimal gathering points of crabs on a plane for Manhattan and Euclidean fuel.
+func (app *application) alignOnPlane(filePath string) {
+	file, err := os.Open(filePath)
+
+	if err != nil {
+		app.log.Fatalf("Encountered error while opening crab points file (%s).", err.Error())
+	}
 
-	usedFuel := 0
+	// Defer close the file.
+	defer func() {
+		err = file.Close()
 
-	for _, position := range crabPositions {
-		positionDiff := mean - position
-		if position > mean {
-			positionDiff = position - mean
+		if err != nil {
+			app.log.Printf("Failed to close file: %s\n", filePath)
 		}
+	}()
 
-		usedFuel += positionDiff * (positionDiff + 1) / 2
+	points, err := crab.ParsePoints(file)
+
+	if err != nil {
+		app.log.Fatalf("Encountered error during crab points file parsing (%s).", err.Error())
 	}
 
-	return usedFuel
+	metrics := []crab.Metric{crab.Manhattan, crab.Euclidean}
+	names := []string{"Manhattan", "Euclidean"}
+
+	for i, metric := range metrics {
+		alignment, err := crab.SolvePlane(points, metric)
+
+		if err != nil {
+			app.log.Fatalf("Encountered error while aligning crabs (%s).", err.Error())
+		}
+
+		fmt.Printf("%s optimum (%.4f, %.4f) uses %.4f fuel, best integer point (%d, %d) uses %.4f fuel.\n", names[i],
+			alignment.X, alignment.Y, alignment.Fuel, alignment.Lattice.X, alignment.Lattice.Y, alignment.LatticeFuel)
+	}
+}
+
+// alignStreamed streams crab positions into a histogram and prints statistics and fuel of both parts.
+func (app *application) alignStreamed(filePath string) {
+	file, err := os.Open(filePath)
+
+	if err != nil {
+		app.log.Fatalf("Encountered error while opening crab positions file (%s).", err.Error())
+	}
+
+	// Defer close the file.
+	defer func() {
+		err = file.Close()
+
+		if err != nil {
+			app.log.Printf("Failed to close file: %s\n", filePath)
+		}
+	}()
+
+	histogram, err := crab.ReadHistogram(file)
+
+	if err != nil {
+		app.log.Fatalf("Encountered error during crab positions file parsing (%s).", err.Error())
+	}
+
+	fmt.Printf("Crabs: %d, median: %d, mean: %.4f\n", histogram.Total, histogram.Median(), histogram.Mean())
+
+	partOne, _ := histogram.Solve(crab.LinearCost{})
+	partTwo, _ := histogram.Solve(crab.TriangularCost{})
+
+	fmt.Printf("Fuel used part one: %s\n", partOne.FuelString())
+	fmt.Printf("Fuel used part two: %s\n", partTwo.FuelString())
+}
+
+// writeFuelProfile writes total fuel of every candidate position as CSV to the provided file.
+func (app *application) writeFuelProfile(profile *crab.FuelProfile, filePath string) {
+	file, err := os.Create(filePath)
+
+	if err != nil {
+		app.log.Fatalf("Encountered error while creating fuel curve file (%s).", err.Error())
+	}
+
+	// Defer close the file.
+	defer func() {
+		err = file.Close()
+
+		if err != nil {
+			app.log.Printf("Failed to close file: %s\n", filePath)
+		}
+	}()
+
+	if err = profile.WriteCSV(file); err != nil {
+		app.log.Fatalf("Encountered error while writing fuel curve (%s).", err.Error())
+	}
+}
+
+// printReport prints all positions tied for the optimum and the fuel each crab spends at the position chosen by
+// crab.Solve.
+func (app *application) printReport(crabPositions []int, cost crab.CostFunction, profile *crab.FuelProfile) {
+	_, optimal := profile.Optimum()
+	alignment := app.computeFuelConsumption(crabPositions, cost)
+	fmt.Printf("Minimal fuel %s at %d position(s): %v\n", alignment.FuelString(), len(optimal), optimal)
+	fmt.Printf("Fuel spent by each crab when aligning at chosen position %d:\n", alignment.Position)
+
+	crabFuel, err := crab.CrabFuel(crabPositions, cost, alignment.Position)
+
+	if err != nil {
+		app.log.Fatalf("Encountered error while computing fuel of each crab (%s).", err.Error())
+	}
+
+	for i, fuel := range crabFuel {
+		fmt.Printf("crab %d at position %d: %g\n", i, crabPositions[i], fuel)
+	}
 }
 
 func main() {
 	var fishFile = flag.String("file", "input.txt", "Crab positions file.")
+	var costName = flag.String("cost", "",
+		"Align with this cost function (linear, triangular, quadratic or power:P, defaults to linear with -curve, -plot "+
+			"and -report).")
+	var weightsFile = flag.String("weights", "", "Per-crab fuel weights file used with -cost (empty disables).")
+	var clusters = flag.Int("clusters", 1, "Number of alignment positions crabs may gather at (used with -cost).")
+	var pointsFile = flag.String("points", "",
+		"Align crabs on a plane read from this file with one x,y position per line (empty disables).")
+	var stream = flag.Bool("stream", false, "Stream crab positions into a histogram instead of loading them all.")
+	var curveFile = flag.String("curve", "",
+		"Write total fuel of every candidate position as CSV to this file (uses -cost, empty disables).")
+	var plot = flag.Bool("plot", false, "Plot total fuel of every candidate position (uses -cost).")
+	var plotWidth = flag.Int("plot-width", 80, "Number of plot columns.")
+	var plotHeight = flag.Int("plot-height", 20, "Number of plot rows.")
+	var report = flag.Bool("report", false,
+		"Print all optimal positions and fuel spent by each crab at the one chosen by the solver (uses -cost).")
 	flag.Parse()
 
 	app := application{log: log.Default()}
 
+	if *pointsFile != "" {
+		app.alignOnPlane(*pointsFile)
+		return
+	}
+
+	if *stream {
+		app.alignStreamed(*fishFile)
+		return
+	}
+
 	crabPositions, err := app.parseCrabPositionsFile(*fishFile)
 
 	if err != nil {
 		app.log.Fatalf("Encountered error during crab positions file parsing (%s).", err.Error())
 	}
 
-	fmt.Printf("Fuel used part one: %d\n", computeFuelConsumptionPartOne(crabPositions))
-	fmt.Printf("Fuel used part two: %d\n", computeFuelConsumptionPartTwo(crabPositions))
+	inspect := *curveFile != "" || *plot || *report
+
+	if *costName == "" && !inspect {
+		// Each move costs 1 fuel unit in part one. Each subsequent move is more costly in part two, for example 4 moves of
+		// the same crab cost (1 + 2 + 3 + 4).
+		fmt.Printf("Fuel used part one: %s\n", app.computeFuelConsumption(crabPositions, crab.LinearCost{}).FuelString())
+		fmt.Printf("Fuel used part two: %s\n",
+			app.computeFuelConsumption(crabPositions, crab.TriangularCost{}).FuelString())
+		return
+	}
+
+	if *costName == "" {
+		*costName = "linear"
+	}
+
+	cost, err := crab.ParseCostFunction(*costName)
+
+	if err != nil {
+		app.log.Fatalf("Encountered error while parsing cost function (%s).", err.Error())
+	}
+
+	if *weightsFile != "" {
+		weights, err := app.parseWeightsFile(*weightsFile)
+
+		if err != nil {
+			app.log.Fatalf("Encountered error during weights file parsing (%s).", err.Error())
+		}
+
+		if cost, err = crab.NewWeightedCost(cost, weights); err != nil {
+			app.log.Fatalf("Invalid weights (%s).", err.Error())
+		}
+	}
+
+	if inspect {
+		if *clusters > 1 {
+			app.log.Fatalf("Option -clusters cannot be combined with -curve, -plot and -report.")
+		}
+
+		profile, err := crab.ComputeFuelProfile(crabPositions, cost)
+
+		if err != nil {
+			app.log.Fatalf("Encountered error while computing fuel profile (%s).", err.Error())
+		}
+
+		if *curveFile != "" {
+			app.writeFuelProfile(profile, *curveFile)
+		}
+
+		if *plot {
+			if err = profile.Plot(os.Stdout, *plotWidth, *plotHeight); err != nil {
+				app.log.Fatalf("Encountered error while plotting fuel profile (%s).", err.Error())
+			}
+		}
+
+		if *report {
+			app.printReport(crabPositions, cost, profile)
+		}
+
+		return
+	}
+
+	if *clusters > 1 {
+		app.printClusters(crabPositions, cost, *clusters)
+		return
+	}
+
+	alignment := app.computeFuelConsumption(crabPositions, cost)
+	fmt.Printf("Optimal position %d uses %s fuel.\n", alignment.Position, alignment.FuelString())
 }
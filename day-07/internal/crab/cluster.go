@@ -0,0 +1,135 @@
+package crab
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// MaxClusterTableSize is the largest number of (crab, position) fuel prefix sums and of crab runs SolveClusters may
+// allocate.
+const MaxClusterTableSize = 1 << 25
+
+// Cluster is a group of crabs aligned at the same position.
+type Cluster struct {
+	Position int
+	Crabs    []int
+	Fuel     float64
+}
+
+// Clustering assigns crabs to several alignment positions.
+type Clustering struct {
+	Clusters []Cluster
+	Fuel     float64
+}
+
+// SolveClusters chooses k alignment positions and assigns each crab to one of them so that the total fuel is minimal.
+// Fuel never decreases with distance, so every crab moves to its nearest position and clusters are contiguous runs of
+// crabs sorted by position. The best split into k runs is found by dynamic programming over the sorted crabs. Cost of a
+// run is minimized like in Solve, using prefix sums of per-crab fuel over all positions.
+func SolveClusters(positions []int, cost CostFunction, k int) (*Clustering, error) {
+	n := len(positions)
+
+	if k < 1 || k > n {
+		return nil, errors.New(fmt.Sprintf("cluster count %d must be in range [1, %d]", k, n))
+	}
+
+	if err := checkWeights(positions, cost); err != nil {
+		return nil, err
+	}
+
+	low, high := positionRange(positions)
+	width := high - low + 1
+
+	if n+1 > MaxClusterTableSize/width || n+1 > MaxClusterTableSize/n {
+		return nil, errors.New(fmt.Sprintf("%d crabs spanning %d positions exceed the fuel table limit", n, width))
+	}
+
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		return positions[order[a]] < positions[order[b]]
+	})
+
+	// prefix[j*width+t] is fuel of the first j sorted crabs aligned at position low + t.
+	prefix := make([]float64, (n+1)*width)
+
+	for j, crab := range order {
+		for t := 0; t < width; t++ {
+			distance := positions[crab] - (low + t)
+
+			if distance < 0 {
+				distance = -distance
+			}
+
+			prefix[(j+1)*width+t] = prefix[j*width+t] + cost.Fuel(crab, distance)
+		}
+	}
+
+	// runs[i*(n+1)+j] is the best alignment of sorted crabs [i, j).
+	runs := make([]Alignment, n*(n+1))
+
+	for i := 0; i < n; i++ {
+		for j := i + 1; j <= n; j++ {
+			runs[i*(n+1)+j] = minimize(positions[order[i]], positions[order[j-1]], cost.Convex(), func(target int) float64 {
+				t := target - low
+				return prefix[j*width+t] - prefix[i*width+t]
+			})
+		}
+	}
+
+	// best[c][j] is minimal fuel of the first j sorted crabs split into c runs, split[c][j] start of the last run.
+	best := make([][]float64, k+1)
+	split := make([][]int, k+1)
+
+	for c := range best {
+		best[c] = make([]float64, n+1)
+		split[c] = make([]int, n+1)
+
+		for j := range best[c] {
+			best[c][j] = math.Inf(1)
+		}
+	}
+
+	best[0][0] = 0
+
+	for c := 1; c <= k; c++ {
+		for j := c; j <= n; j++ {
+			for i := c - 1; i < j; i++ {
+				if fuel := best[c-1][i] + runs[i*(n+1)+j].Fuel; fuel < best[c][j] {
+					best[c][j] = fuel
+					split[c][j] = i
+				}
+			}
+		}
+	}
+
+	clustering := Clustering{Clusters: make([]Cluster, k)}
+
+	for c, j := k, n; c > 0; c-- {
+		i := split[c][j]
+		cluster := Cluster{Position: runs[i*(n+1)+j].Position, Crabs: append([]int(nil), order[i:j]...)}
+		sort.Ints(cluster.Crabs)
+
+		// Fuel is summed directly to avoid rounding errors of the prefix sums.
+		for _, crab := range cluster.Crabs {
+			distance := positions[crab] - cluster.Position
+
+			if distance < 0 {
+				distance = -distance
+			}
+
+			cluster.Fuel += cost.Fuel(crab, distance)
+		}
+
+		clustering.Clusters[c-1] = cluster
+		clustering.Fuel += cluster.Fuel
+		j = i
+	}
+
+	return &clustering, nil
+}
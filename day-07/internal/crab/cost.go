@@ -0,0 +1,155 @@
+package crab
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// CostFunction computes the fuel crabs spend to move.
+type CostFunction interface {
+	// Fuel returns fuel the crab with index crab spends to move distance (>= 0) steps.
+	Fuel(crab int, distance int) float64
+	// Convex reports whether Fuel is convex and non-decreasing in distance for every crab, which makes total fuel convex
+	// in the alignment position.
+	Convex() bool
+}
+
+// IntegerCost is a CostFunction whose fuel is always an integer, which lets solvers sum and compare fuel exactly.
+type IntegerCost interface {
+	CostFunction
+	// ExactFuel sets fuel to the fuel the crab with index crab spends to move distance (>= 0) steps and returns it.
+	ExactFuel(crab int, distance int, fuel *big.Int) *big.Int
+}
+
+// LinearCost each step costs 1 fuel unit.
+type LinearCost struct{}
+
+// Fuel returns distance.
+func (LinearCost) Fuel(_ int, distance int) float64 {
+	return float64(distance)
+}
+
+// ExactFuel sets fuel to distance.
+func (LinearCost) ExactFuel(_ int, distance int, fuel *big.Int) *big.Int {
+	return fuel.SetInt64(int64(distance))
+}
+
+// Convex returns true.
+func (LinearCost) Convex() bool {
+	return true
+}
+
+// TriangularCost each step costs 1 more fuel unit than the previous one, so distance d costs 1 + 2 + ... + d.
+type TriangularCost struct{}
+
+// Fuel returns distance * (distance + 1) / 2.
+func (TriangularCost) Fuel(_ int, distance int) float64 {
+	return float64(distance) * float64(distance+1) / 2
+}
+
+// ExactFuel sets fuel to distance * (distance + 1) / 2.
+func (TriangularCost) ExactFuel(_ int, distance int, fuel *big.Int) *big.Int {
+	d := int64(distance)
+
+	if d%2 == 0 {
+		return fuel.Mul(fuel.SetInt64(d/2), big.NewInt(d+1))
+	}
+
+	return fuel.Mul(fuel.SetInt64(d), big.NewInt(d/2+1))
+}
+
+// Convex returns true.
+func (TriangularCost) Convex() bool {
+	return true
+}
+
+// QuadraticCost moving distance d costs d * d fuel units.
+type QuadraticCost struct{}
+
+// Fuel returns distance * distance.
+func (QuadraticCost) Fuel(_ int, distance int) float64 {
+	return float64(distance) * float64(distance)
+}
+
+// ExactFuel sets fuel to distance * distance.
+func (QuadraticCost) ExactFuel(_ int, distance int, fuel *big.Int) *big.Int {
+	fuel.SetInt64(int64(distance))
+
+	return fuel.Mul(fuel, fuel)
+}
+
+// Convex returns true.
+func (QuadraticCost) Convex() bool {
+	return true
+}
+
+// PowerCost moving distance d costs d^P fuel units.
+type PowerCost struct {
+	P float64
+}
+
+// Fuel returns distance^P.
+func (c PowerCost) Fuel(_ int, distance int) float64 {
+	return math.Pow(float64(distance), c.P)
+}
+
+// Convex returns true if P >= 1.
+func (c PowerCost) Convex() bool {
+	return c.P >= 1
+}
+
+// WeightedCost scales the fuel of each crab by its weight. It may be used as a value or a pointer, solvers check that
+// it has a weight for every crab in both cases.
+type WeightedCost struct {
+	Base    CostFunction
+	Weights []float64
+}
+
+// Fuel returns fuel of the base cost function multiplied by the crab's weight.
+func (c WeightedCost) Fuel(crab int, distance int) float64 {
+	return c.Weights[crab] * c.Base.Fuel(crab, distance)
+}
+
+// Convex returns true if the base cost is convex, weights are non-negative.
+func (c WeightedCost) Convex() bool {
+	return c.Base.Convex()
+}
+
+// NewWeightedCost creates a WeightedCost and checks that weights are non-negative.
+func NewWeightedCost(base CostFunction, weights []float64) (*WeightedCost, error) {
+	for i, weight := range weights {
+		if weight < 0 || math.IsNaN(weight) {
+			return nil, errors.New(fmt.Sprintf("weight %d (%g) must be non-negative", i+1, weight))
+		}
+	}
+
+	return &WeightedCost{Base: base, Weights: weights}, nil
+}
+
+// ParseCostFunction converts a name (linear, triangular, quadratic or power:P) to a CostFunction.
+func ParseCostFunction(name string) (CostFunction, error) {
+	switch name {
+	case "linear":
+		return LinearCost{}, nil
+	case "triangular":
+		return TriangularCost{}, nil
+	case "quadratic":
+		return QuadraticCost{}, nil
+	}
+
+	if strings.HasPrefix(name, "power:") {
+		p, err := strconv.ParseFloat(strings.TrimPrefix(name, "power:"), 64)
+
+		if err != nil || p < 0 || math.IsInf(p, 0) {
+			return nil, errors.New(fmt.Sprintf("bad exponent of cost function '%s'", name))
+		}
+
+		return PowerCost{P: p}, nil
+	}
+
+	return nil, errors.New(fmt.Sprintf("unknown cost function '%s'", name))
+}
@@ -0,0 +1,481 @@
+package crab
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+)
+
+// MaxHistogramRange is the largest span of positions a Histogram may hold.
+const MaxHistogramRange = 1 << 26
+
+// MaxGenericCurveRange is the largest span of positions for which fuel curves of weighted costs and of costs other
+// than linear, triangular and quadratic are computed. Those are evaluated position by position.
+const MaxGenericCurveRange = 1 << 13
+
+// Histogram counts crabs at each position in range [Min, Min + len(Counts)).
+type Histogram struct {
+	Min    int
+	Counts []int64
+	Total  int64
+	// lowest and highest occupied positions, valid if bounded is set.
+	lowest, highest int
+	bounded         bool
+}
+
+// Max returns the largest position of the histogram range.
+func (h *Histogram) Max() int {
+	return h.Min + len(h.Counts) - 1
+}
+
+// occupiedRange returns the lowest and the highest position holding a crab. Range of a histogram without crabs is
+// [Min, Min].
+func (h *Histogram) occupiedRange() (int, int) {
+	if !h.bounded {
+		low, high := 0, len(h.Counts)-1
+
+		for low < high && h.Counts[low] == 0 {
+			low++
+		}
+
+		for high > low && h.Counts[high] == 0 {
+			high--
+		}
+
+		h.lowest, h.highest, h.bounded = h.Min+low, h.Min+high, true
+	}
+
+	return h.lowest, h.highest
+}
+
+// Add adds a crab at the provided position and grows the range if needed. Occupied positions may span at most
+// MaxHistogramRange values.
+func (h *Histogram) Add(position int) error {
+	if len(h.Counts) == 0 {
+		h.Min, h.Counts = position, make([]int64, 1, 64)
+		h.lowest, h.highest, h.bounded = position, position, true
+	}
+
+	lowest, highest := h.occupiedRange()
+
+	if h.Total == 0 {
+		lowest, highest = position, position
+	}
+
+	if position < h.Min || position > h.Max() {
+		low, high := lowest, highest
+
+		if position < low {
+			low = position
+		} else if position > high {
+			high = position
+		}
+
+		if uint(high)-uint(low) >= MaxHistogramRange {
+			return errors.New(fmt.Sprintf("positions span more than %d values", MaxHistogramRange))
+		}
+
+		// Grow geometrically to keep additions amortized O(1).
+		size := 2 * len(h.Counts)
+
+		if size < high-low+1 {
+			size = high - low + 1
+		}
+
+		if size > MaxHistogramRange {
+			size = MaxHistogramRange
+		}
+
+		counts := make([]int64, size)
+		newMin := low
+
+		if position < h.Min {
+			// Leave spare room below when growing downwards.
+			newMin = high - size + 1
+		}
+
+		if h.Total > 0 {
+			copy(counts[lowest-newMin:], h.Counts[lowest-h.Min:highest-h.Min+1])
+		}
+
+		h.Min, h.Counts = newMin, counts
+	}
+
+	h.Counts[position-h.Min]++
+	h.Total++
+
+	if position < lowest {
+		lowest = position
+	} else if position > highest {
+		highest = position
+	}
+
+	h.lowest, h.highest, h.bounded = lowest, highest, true
+
+	return nil
+}
+
+// ReadHistogram streams comma separated crab positions without holding them in memory. Whitespace and line breaks
+// between records are ignored.
+func ReadHistogram(r io.Reader) (*Histogram, error) {
+	reader := bufio.NewReaderSize(r, 1<<16)
+	h := Histogram{}
+
+	value, digits, negative, record := 0, 0, false, 1
+
+	finishRecord := func() error {
+		if digits == 0 {
+			return errors.New(fmt.Sprintf("record %d is empty or not an integer", record))
+		}
+
+		if negative {
+			value = -value
+		}
+
+		if err := h.Add(value); err != nil {
+			return errors.New(fmt.Sprintf("record %d: %s", record, err.Error()))
+		}
+
+		value, digits, negative = 0, 0, false
+		record++
+
+		return nil
+	}
+
+	for {
+		b, err := reader.ReadByte()
+
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		switch {
+		case b >= '0' && b <= '9':
+			if value > (math.MaxInt32-int(b-'0'))/10 {
+				return nil, errors.New(fmt.Sprintf("record %d is out of range", record))
+			}
+
+			value = value*10 + int(b-'0')
+			digits++
+		case b == '-' && digits == 0 && !negative:
+			negative = true
+		case b == ',':
+			if err := finishRecord(); err != nil {
+				return nil, err
+			}
+		case b == ' ' || b == '\t' || b == '\n' || b == '\r':
+		default:
+			return nil, errors.New(fmt.Sprintf("record %d has unexpected character %q", record, b))
+		}
+	}
+
+	// Trailing record without a comma.
+	if digits > 0 || negative {
+		if err := finishRecord(); err != nil {
+			return nil, err
+		}
+	}
+
+	if h.Total == 0 {
+		return nil, errors.New("no crab positions")
+	}
+
+	h.Compact()
+
+	return &h, nil
+}
+
+// Compact shrinks the range to the positions between the outermost crabs.
+func (h *Histogram) Compact() {
+	lowest, highest := h.occupiedRange()
+
+	h.Counts = append([]int64(nil), h.Counts[lowest-h.Min:highest-h.Min+1]...)
+	h.Min = lowest
+}
+
+// nth returns the position of the k-th (0-based) crab in sorted order.
+func (h *Histogram) nth(k int64) int {
+	for i, count := range h.Counts {
+		if k < count {
+			return h.Min + i
+		}
+
+		k -= count
+	}
+
+	return h.Max()
+}
+
+// Median returns the median position like util.ComputeMedian, the middle values are averaged for even crab counts.
+func (h *Histogram) Median() int {
+	if h.Total%2 != 0 {
+		return h.nth(h.Total / 2)
+	}
+
+	return (h.nth(h.Total/2-1) + h.nth(h.Total/2)) / 2
+}
+
+// Mean returns the mean position.
+func (h *Histogram) Mean() float64 {
+	sum := 0.0
+
+	for i, count := range h.Counts {
+		sum += float64(count) * float64(h.Min+i)
+	}
+
+	return sum / float64(h.Total)
+}
+
+// sweepFuel calls visit with exact total fuel at every position Min+i in increasing order for linear, triangular and
+// quadratic costs and reports false for other costs. With A and B sums of count*j^2 and count*j over histogram offsets
+// j, and below and belowSum count and count*j sums of crabs left of offset i, linear fuel at i is
+// i*(2*below - Total) + B - 2*belowSum, quadratic fuel is A + i*(Total*i - 2*B) and triangular fuel is their mean.
+// Values are computed with int64 if Total*range^2 is below 2^60 and with math/big otherwise. Fuel passed to visit is
+// reused between calls.
+func (h *Histogram) sweepFuel(cost CostFunction, visit func(i int, fuel *big.Int)) bool {
+	fuelOf := func(linear, quadratic int64) int64 { return linear }
+
+	switch cost.(type) {
+	case LinearCost:
+	case TriangularCost:
+		fuelOf = func(linear, quadratic int64) int64 { return (linear + quadratic) / 2 }
+	case QuadraticCost:
+		fuelOf = func(linear, quadratic int64) int64 { return quadratic }
+	default:
+		return false
+	}
+
+	r := float64(len(h.Counts))
+
+	if float64(h.Total)*r*r >= 1<<60 {
+		h.sweepFuelBig(cost, visit)
+		return true
+	}
+
+	var a, b int64
+
+	for j, count := range h.Counts {
+		a += count * int64(j) * int64(j)
+		b += count * int64(j)
+	}
+
+	fuel := new(big.Int)
+	var below, belowSum int64
+
+	for i, count := range h.Counts {
+		offset := int64(i)
+		linear := offset*(2*below-h.Total) + b - 2*belowSum
+		quadratic := a + offset*(h.Total*offset-2*b)
+		visit(i, fuel.SetInt64(fuelOf(linear, quadratic)))
+
+		below += count
+		belowSum += count * offset
+	}
+
+	return true
+}
+
+// sweepFuelBig is sweepFuel with math/big arithmetic.
+func (h *Histogram) sweepFuelBig(cost CostFunction, visit func(i int, fuel *big.Int)) {
+	a, b, total := new(big.Int), new(big.Int), big.NewInt(h.Total)
+	below, belowSum := new(big.Int), new(big.Int)
+	offset, count, tmp := new(big.Int), new(big.Int), new(big.Int)
+	linear, quadratic := new(big.Int), new(big.Int)
+
+	for j, c := range h.Counts {
+		offset.SetInt64(int64(j))
+		count.SetInt64(c)
+		tmp.Mul(count, offset)
+		b.Add(b, tmp)
+		a.Add(a, tmp.Mul(tmp, offset))
+	}
+
+	for i, c := range h.Counts {
+		offset.SetInt64(int64(i))
+
+		linear.Lsh(below, 1)
+		linear.Mul(linear.Sub(linear, total), offset)
+		linear.Sub(linear.Add(linear, b), tmp.Lsh(belowSum, 1))
+
+		quadratic.Mul(total, offset)
+		quadratic.Mul(quadratic.Sub(quadratic, tmp.Lsh(b, 1)), offset)
+		quadratic.Add(quadratic, a)
+
+		switch cost.(type) {
+		case LinearCost:
+			visit(i, linear)
+		case TriangularCost:
+			visit(i, quadratic.Rsh(quadratic.Add(quadratic, linear), 1))
+		case QuadraticCost:
+			visit(i, quadratic)
+		}
+
+		count.SetInt64(c)
+		below.Add(below, count)
+		belowSum.Add(belowSum, tmp.Mul(count, offset))
+	}
+}
+
+// FuelCurve returns total fuel for every position in [Min, Max()]. Linear, triangular and quadratic costs are computed
+// exactly in O(range) time and rounded to float64 once per position. Other unweighted costs are evaluated in
+// O(range^2) time for ranges of at most MaxGenericCurveRange positions.
+func (h *Histogram) FuelCurve(cost CostFunction) ([]float64, error) {
+	curve, _, _, err := h.fuelCurve(cost)
+
+	return curve, err
+}
+
+// fuelCurve is FuelCurve that additionally returns the exact minimal fuel and the offsets of all positions tied for it
+// if the cost is an IntegerCost, nil otherwise.
+func (h *Histogram) fuelCurve(cost CostFunction) ([]float64, *big.Int, []int, error) {
+	if err := h.checkCurveCost(cost); err != nil {
+		return nil, nil, nil, err
+	}
+
+	curve := make([]float64, len(h.Counts))
+	var best *big.Int
+	var optimal []int
+
+	exact := h.sweepFuel(cost, func(i int, fuel *big.Int) {
+		if fuel.IsInt64() {
+			curve[i] = float64(fuel.Int64())
+		} else {
+			curve[i], _ = new(big.Float).SetInt(fuel).Float64()
+		}
+
+		if best == nil || fuel.Cmp(best) < 0 {
+			best, optimal = new(big.Int).Set(fuel), []int{i}
+		} else if fuel.Cmp(best) == 0 {
+			optimal = append(optimal, i)
+		}
+	})
+
+	if exact {
+		return curve, best, optimal, nil
+	}
+
+	for t := range curve {
+		for p, count := range h.Counts {
+			if count > 0 {
+				distance := t - p
+
+				if distance < 0 {
+					distance = -distance
+				}
+
+				curve[t] += float64(count) * cost.Fuel(0, distance)
+			}
+		}
+	}
+
+	if integerCost, ok := cost.(IntegerCost); ok {
+		best, optimal = h.exactOptimum(integerCost, curve)
+	}
+
+	return curve, best, optimal, nil
+}
+
+// exactOptimum finds the exact minimal fuel of an IntegerCost and the offsets tied for it. Rounding error of the
+// floating point curve is below range * 2^-53 relative, so only positions within 1e-9 of the floating point minimum
+// are summed exactly.
+func (h *Histogram) exactOptimum(cost IntegerCost, curve []float64) (*big.Int, []int) {
+	lowest := math.Inf(1)
+
+	for _, fuel := range curve {
+		lowest = math.Min(lowest, fuel)
+	}
+
+	var best *big.Int
+	var optimal []int
+	total, term, count := new(big.Int), new(big.Int), new(big.Int)
+
+	for t, fuel := range curve {
+		if fuel-lowest > 1e-9*math.Max(1, lowest) {
+			continue
+		}
+
+		total.SetInt64(0)
+
+		for p, c := range h.Counts {
+			if c > 0 {
+				distance := t - p
+
+				if distance < 0 {
+					distance = -distance
+				}
+
+				cost.ExactFuel(0, distance, term)
+				total.Add(total, term.Mul(term, count.SetInt64(c)))
+			}
+		}
+
+		if best == nil || total.Cmp(best) < 0 {
+			best, optimal = new(big.Int).Set(total), []int{t}
+		} else if total.Cmp(best) == 0 {
+			optimal = append(optimal, t)
+		}
+	}
+
+	return best, optimal
+}
+
+// checkCurveCost checks that fuel curve of the cost can be computed from the histogram.
+func (h *Histogram) checkCurveCost(cost CostFunction) error {
+	if _, weighted := weightsOf(cost); weighted {
+		return errors.New("histogram does not keep crab identities needed by weighted costs")
+	}
+
+	switch cost.(type) {
+	case LinearCost, TriangularCost, QuadraticCost:
+		return nil
+	}
+
+	if len(h.Counts) > MaxGenericCurveRange {
+		return errors.New(fmt.Sprintf("positions span %d values, fuel curve of %T is limited to %d values",
+			len(h.Counts), cost, MaxGenericCurveRange))
+	}
+
+	return nil
+}
+
+// Solve finds the smallest position with minimal fuel on the fuel curve. Linear, triangular and quadratic fuel is
+// compared exactly.
+func (h *Histogram) Solve(cost CostFunction) (Alignment, error) {
+	if err := h.checkCurveCost(cost); err != nil {
+		return Alignment{}, err
+	}
+
+	var best *big.Int
+	bestIdx := 0
+
+	exact := h.sweepFuel(cost, func(i int, fuel *big.Int) {
+		if best == nil || fuel.Cmp(best) < 0 {
+			best, bestIdx = new(big.Int).Set(fuel), i
+		}
+	})
+
+	if exact {
+		return exactAlignment(h.Min+bestIdx, best), nil
+	}
+
+	curve, err := h.FuelCurve(cost)
+
+	if err != nil {
+		return Alignment{}, err
+	}
+
+	alignment := Alignment{Position: h.Min, Fuel: curve[0]}
+
+	for t, fuel := range curve {
+		if fuel < alignment.Fuel {
+			alignment = Alignment{Position: h.Min + t, Fuel: fuel}
+		}
+	}
+
+	return alignment, nil
+}
@@ -0,0 +1,275 @@
+package crab
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// weiszfeldIterations bounds the number of Weiszfeld iterations.
+const weiszfeldIterations = 100000
+
+// weiszfeldTolerance stops Weiszfeld iteration when a step moves less than this distance.
+const weiszfeldTolerance = 1e-10
+
+// Point is a crab position on a plane.
+type Point struct {
+	X int
+	Y int
+}
+
+// Metric measures the fuel of moving on a plane.
+type Metric int
+
+const (
+	// Manhattan moves cost |dx| + |dy|.
+	Manhattan Metric = iota
+	// Euclidean moves cost sqrt(dx^2 + dy^2).
+	Euclidean
+)
+
+// PlaneAlignment is the optimal gathering point of crabs on a plane.
+type PlaneAlignment struct {
+	// X and Y are coordinates of the optimal real point.
+	X    float64
+	Y    float64
+	Fuel float64
+	// Lattice is the best integer point.
+	Lattice     Point
+	LatticeFuel float64
+}
+
+// ParsePoints reads one "x,y" crab position per line. Empty lines are skipped.
+func ParsePoints(r io.Reader) ([]Point, error) {
+	scanner := bufio.NewScanner(r)
+
+	var points []Point
+
+	for lineIdx := 1; scanner.Scan(); lineIdx++ {
+		text := strings.TrimSpace(scanner.Text())
+
+		if text == "" {
+			continue
+		}
+
+		parts := strings.Split(text, ",")
+
+		if len(parts) != 2 {
+			return nil, errors.New(fmt.Sprintf("line %d (%s) is not in x,y format", lineIdx, text))
+		}
+
+		x, errX := strconv.Atoi(strings.TrimSpace(parts[0]))
+		y, errY := strconv.Atoi(strings.TrimSpace(parts[1]))
+
+		if errX != nil || errY != nil {
+			return nil, errors.New(fmt.Sprintf("line %d (%s) has non-integer coordinates", lineIdx, text))
+		}
+
+		points = append(points, Point{X: x, Y: y})
+	}
+
+	return points, scanner.Err()
+}
+
+// PlaneFuel computes the fuel crabs spend to gather at (x, y).
+func PlaneFuel(points []Point, metric Metric, x, y float64) float64 {
+	fuel := 0.0
+
+	for _, p := range points {
+		dx, dy := float64(p.X)-x, float64(p.Y)-y
+
+		if metric == Manhattan {
+			fuel += math.Abs(dx) + math.Abs(dy)
+		} else {
+			fuel += math.Hypot(dx, dy)
+		}
+	}
+
+	return fuel
+}
+
+// SolvePlane finds the optimal real and integer gathering points. Manhattan fuel is separable, so the coordinate-wise
+// median is optimal. Euclidean fuel is minimized at the geometric median, which is found by Weiszfeld iteration.
+func SolvePlane(points []Point, metric Metric) (*PlaneAlignment, error) {
+	if len(points) == 0 {
+		return nil, errors.New("cannot align without crabs")
+	}
+
+	alignment := PlaneAlignment{}
+
+	if metric == Manhattan {
+		xs, ys := make([]int, len(points)), make([]int, len(points))
+
+		for i, p := range points {
+			xs[i], ys[i] = p.X, p.Y
+		}
+
+		sort.Ints(xs)
+		sort.Ints(ys)
+
+		// Any point between the middle values is optimal, the lower one is an integer.
+		mid := len(points) / 2
+
+		if len(points)%2 == 0 {
+			alignment.X, alignment.Y = float64(xs[mid-1]+xs[mid])/2, float64(ys[mid-1]+ys[mid])/2
+			alignment.Lattice = Point{X: xs[mid-1], Y: ys[mid-1]}
+		} else {
+			alignment.X, alignment.Y = float64(xs[mid]), float64(ys[mid])
+			alignment.Lattice = Point{X: xs[mid], Y: ys[mid]}
+		}
+	} else {
+		alignment.X, alignment.Y = geometricMedian(points)
+		alignment.Lattice = bestLatticePoint(points, metric, alignment.Y)
+	}
+
+	alignment.Fuel = PlaneFuel(points, metric, alignment.X, alignment.Y)
+	alignment.LatticeFuel = PlaneFuel(points, metric, float64(alignment.Lattice.X), float64(alignment.Lattice.Y))
+
+	return &alignment, nil
+}
+
+// geometricMedian runs Weiszfeld iteration from the centroid. When the iterate coincides with crabs, the modified step
+// of Vardi and Zhang is used, so the iteration cannot get stuck at a crab that is not optimal.
+func geometricMedian(points []Point) (float64, float64) {
+	x, y := 0.0, 0.0
+
+	for _, p := range points {
+		x += float64(p.X)
+		y += float64(p.Y)
+	}
+
+	x, y = x/float64(len(points)), y/float64(len(points))
+
+	for i := 0; i < weiszfeldIterations; i++ {
+		var sumX, sumY, sumW, rx, ry float64
+		coincident := 0.0
+
+		for _, p := range points {
+			dx, dy := float64(p.X)-x, float64(p.Y)-y
+			distance := math.Hypot(dx, dy)
+
+			if distance < 1e-12 {
+				coincident++
+				continue
+			}
+
+			sumX += float64(p.X) / distance
+			sumY += float64(p.Y) / distance
+			sumW += 1 / distance
+			rx += dx / distance
+			ry += dy / distance
+		}
+
+		if sumW == 0 {
+			break
+		}
+
+		nextX, nextY := sumX/sumW, sumY/sumW
+
+		if coincident > 0 {
+			r := math.Hypot(rx, ry)
+
+			// Pull of the other crabs does not outweigh the crabs at the iterate, so it is optimal.
+			if r <= coincident {
+				break
+			}
+
+			nextX = (1-coincident/r)*nextX + coincident/r*x
+			nextY = (1-coincident/r)*nextY + coincident/r*y
+		}
+
+		step := math.Hypot(nextX-x, nextY-y)
+		x, y = nextX, nextY
+
+		if step < weiszfeldTolerance {
+			break
+		}
+	}
+
+	return x, y
+}
+
+// bestLatticePoint finds the integer point with minimal fuel for a convex metric. Fuel restricted to a row is convex,
+// so the best integer point of a row is found by ternary search. The real minimum of a row is convex in y and bounds
+// the integer minimum from below, so rows are visited outwards from the optimal real y until that bound exceeds the best
+// integer fuel.
+func bestLatticePoint(points []Point, metric Metric, optimumY float64) Point {
+	minX, maxX := points[0].X, points[0].X
+	minY, maxY := points[0].Y, points[0].Y
+
+	for _, p := range points {
+		minX, maxX = minInt(minX, p.X), maxInt(maxX, p.X)
+		minY, maxY = minInt(minY, p.Y), maxInt(maxY, p.Y)
+	}
+
+	best := Point{}
+	bestFuel := math.Inf(1)
+
+	// rowBound returns the real minimum of the row after updating the best integer point of the row.
+	rowBound := func(y int) float64 {
+		row := minimize(minX, maxX, true, func(x int) float64 {
+			return PlaneFuel(points, metric, float64(x), float64(y))
+		})
+
+		if row.Fuel < bestFuel {
+			best, bestFuel = Point{X: row.Position, Y: y}, row.Fuel
+		}
+
+		// Ternary search of the real minimum.
+		low, high := float64(minX), float64(maxX)
+
+		for i := 0; i < 100; i++ {
+			m1, m2 := low+(high-low)/3, high-(high-low)/3
+
+			if PlaneFuel(points, metric, m1, float64(y)) < PlaneFuel(points, metric, m2, float64(y)) {
+				high = m2
+			} else {
+				low = m1
+			}
+		}
+
+		return PlaneFuel(points, metric, (low+high)/2, float64(y))
+	}
+
+	// Relative slack for floating point errors of the bound.
+	slack := func() float64 {
+		return bestFuel + 1e-9*math.Max(1, bestFuel)
+	}
+
+	start := int(math.Floor(optimumY))
+
+	for y := start; y >= minY; y-- {
+		if rowBound(y) > slack() {
+			break
+		}
+	}
+
+	for y := start + 1; y <= maxY; y++ {
+		if rowBound(y) > slack() {
+			break
+		}
+	}
+
+	return best
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
@@ -0,0 +1,236 @@
+package crab
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// FuelProfile holds total fuel for every position in [Min, Min + len(Fuel)).
+type FuelProfile struct {
+	Min  int
+	Fuel []float64
+	// exactFuel minimal fuel of an IntegerCost, nil for other costs.
+	exactFuel *big.Int
+	// optimal positions tied for exactFuel.
+	optimal []int
+}
+
+// ComputeFuelProfile computes total fuel for every position between the outermost crabs. Unweighted costs use
+// Histogram.FuelCurve, weighted costs evaluate every crab at every position of a span of at most MaxGenericCurveRange
+// positions.
+func ComputeFuelProfile(positions []int, cost CostFunction) (*FuelProfile, error) {
+	if len(positions) == 0 {
+		return nil, errors.New("cannot compute fuel profile without crabs")
+	}
+
+	if err := checkWeights(positions, cost); err != nil {
+		return nil, err
+	}
+
+	if _, weighted := weightsOf(cost); !weighted {
+		h := Histogram{}
+
+		for _, position := range positions {
+			if err := h.Add(position); err != nil {
+				return nil, err
+			}
+		}
+
+		h.Compact()
+		curve, best, optimal, err := h.fuelCurve(cost)
+
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range optimal {
+			optimal[i] += h.Min
+		}
+
+		return &FuelProfile{Min: h.Min, Fuel: curve, exactFuel: best, optimal: optimal}, nil
+	}
+
+	low, high := positionRange(positions)
+
+	if uint(high)-uint(low) >= MaxGenericCurveRange {
+		return nil, errors.New(fmt.Sprintf("positions span more than %d values, fuel profile of weighted costs is "+
+			"limited to %d values", MaxGenericCurveRange, MaxGenericCurveRange))
+	}
+
+	profile := FuelProfile{Min: low, Fuel: make([]float64, high-low+1)}
+
+	for i := range profile.Fuel {
+		profile.Fuel[i] = TotalFuel(positions, cost, low+i)
+	}
+
+	return &profile, nil
+}
+
+// Optimum returns the minimal fuel and all positions tied for it. Ties of an unweighted IntegerCost are found by
+// comparing exact fuel, other costs tie within a relative tolerance of 1e-12.
+func (c *FuelProfile) Optimum() (float64, []int) {
+	if c.exactFuel != nil {
+		best, _ := new(big.Float).SetInt(c.exactFuel).Float64()
+
+		return best, append([]int(nil), c.optimal...)
+	}
+
+	best := math.Inf(1)
+
+	for _, fuel := range c.Fuel {
+		best = math.Min(best, fuel)
+	}
+
+	var positions []int
+	tolerance := 1e-12 * math.Max(1, math.Abs(best))
+
+	for i, fuel := range c.Fuel {
+		if fuel-best <= tolerance {
+			positions = append(positions, c.Min+i)
+		}
+	}
+
+	return best, positions
+}
+
+// WriteCSV writes the profile as CSV with columns position and fuel.
+func (c *FuelProfile) WriteCSV(w io.Writer) error {
+	csvWriter := csv.NewWriter(w)
+
+	if err := csvWriter.Write([]string{"position", "fuel"}); err != nil {
+		return err
+	}
+
+	for i, fuel := range c.Fuel {
+		if err := csvWriter.Write([]string{strconv.Itoa(c.Min + i), strconv.FormatFloat(fuel, 'f', -1, 64)}); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+
+	return csvWriter.Error()
+}
+
+// Plot draws the profile as an ASCII chart with the provided number of columns and rows. Each column shows the minimal
+// fuel of the positions it covers, columns holding an optimal position are drawn with '*'.
+func (c *FuelProfile) Plot(w io.Writer, width int, height int) error {
+	if width < 1 || height < 1 {
+		return errors.New(fmt.Sprintf("invalid plot size %dx%d", width, height))
+	}
+
+	if width > len(c.Fuel) {
+		width = len(c.Fuel)
+	}
+
+	best, optimal := c.Optimum()
+	worst := best
+
+	for _, fuel := range c.Fuel {
+		worst = math.Max(worst, fuel)
+	}
+
+	columns := make([]float64, width)
+	marked := make([]bool, width)
+	columnOf := func(i int) int {
+		return i * width / len(c.Fuel)
+	}
+
+	for i := range columns {
+		columns[i] = math.Inf(1)
+	}
+
+	for i, fuel := range c.Fuel {
+		columns[columnOf(i)] = math.Min(columns[columnOf(i)], fuel)
+	}
+
+	for _, position := range optimal {
+		marked[columnOf(position-c.Min)] = true
+	}
+
+	// Number of filled rows of each column, at least one so optimal columns are visible.
+	levels := make([]int, width)
+
+	for i, fuel := range columns {
+		levels[i] = 1
+
+		if worst > best {
+			levels[i] += int(math.Round((fuel - best) / (worst - best) * float64(height-1)))
+		}
+	}
+
+	bw := bufio.NewWriter(w)
+	label := func(value float64) string {
+		return strconv.FormatFloat(value, 'g', 6, 64)
+	}
+	labelWidth := len(label(worst))
+
+	if l := len(label(best)); l > labelWidth {
+		labelWidth = l
+	}
+
+	for row := height; row >= 1; row-- {
+		prefix := ""
+
+		if row == height {
+			prefix = label(worst)
+		} else if row == 1 {
+			prefix = label(best)
+		}
+
+		fmt.Fprintf(bw, "%*s |", labelWidth, prefix)
+
+		for i, level := range levels {
+			switch {
+			case level < row:
+				bw.WriteByte(' ')
+			case marked[i]:
+				bw.WriteByte('*')
+			default:
+				bw.WriteByte('#')
+			}
+		}
+
+		bw.WriteByte('\n')
+	}
+
+	fmt.Fprintf(bw, "%*s +%s\n", labelWidth, "", strings.Repeat("-", width))
+	first, last := strconv.Itoa(c.Min), strconv.Itoa(c.Min+len(c.Fuel)-1)
+	gap := width - len(first) - len(last)
+
+	if gap < 1 {
+		gap = 1
+	}
+
+	fmt.Fprintf(bw, "%*s  %s%s%s\n", labelWidth, "", first, strings.Repeat(" ", gap), last)
+
+	return bw.Flush()
+}
+
+// CrabFuel returns the fuel each crab spends to move to the target position.
+func CrabFuel(positions []int, cost CostFunction, target int) ([]float64, error) {
+	if err := checkWeights(positions, cost); err != nil {
+		return nil, err
+	}
+
+	fuel := make([]float64, len(positions))
+
+	for i, position := range positions {
+		distance := position - target
+
+		if distance < 0 {
+			distance = -distance
+		}
+
+		fuel[i] = cost.Fuel(i, distance)
+	}
+
+	return fuel, nil
+}
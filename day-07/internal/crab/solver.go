@@ -0,0 +1,192 @@
+package crab
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+)
+
+// Alignment is a position crabs align to and the total fuel they spend.
+type Alignment struct {
+	Position int
+	Fuel     float64
+	// Exact total fuel, set for integer costs. Fuel is then Exact rounded to the nearest float64.
+	Exact *big.Int
+}
+
+// FuelString formats the exact total fuel if it is known and the floating point one otherwise.
+func (a Alignment) FuelString() string {
+	if a.Exact != nil {
+		return a.Exact.String()
+	}
+
+	return fmt.Sprintf("%g", a.Fuel)
+}
+
+// compareFuel compares fuel of two alignments, exactly if both are exact.
+func compareFuel(a, b Alignment) int {
+	if a.Exact != nil && b.Exact != nil {
+		return a.Exact.Cmp(b.Exact)
+	}
+
+	if a.Fuel < b.Fuel {
+		return -1
+	} else if a.Fuel > b.Fuel {
+		return 1
+	}
+
+	return 0
+}
+
+// exactAlignment creates an Alignment with exact fuel.
+func exactAlignment(position int, fuel *big.Int) Alignment {
+	approx, _ := new(big.Float).SetInt(fuel).Float64()
+
+	return Alignment{Position: position, Fuel: approx, Exact: fuel}
+}
+
+// TotalFuel computes the fuel crabs at the provided positions spend to align at the target position.
+func TotalFuel(positions []int, cost CostFunction, target int) float64 {
+	fuel := 0.0
+
+	for i, position := range positions {
+		distance := position - target
+
+		if distance < 0 {
+			distance = -distance
+		}
+
+		fuel += cost.Fuel(i, distance)
+	}
+
+	return fuel
+}
+
+// ExactTotalFuel computes the fuel crabs at the provided positions spend to align at the target position exactly. Fuel
+// is summed in int64 as long as it fits and in math/big afterwards.
+func ExactTotalFuel(positions []int, cost IntegerCost, target int) *big.Int {
+	total, term := new(big.Int), new(big.Int)
+	var sum int64
+
+	for i, position := range positions {
+		distance := position - target
+
+		if distance < 0 {
+			distance = -distance
+		}
+
+		cost.ExactFuel(i, distance, term)
+
+		if term.IsInt64() && term.Int64() <= math.MaxInt64-sum {
+			sum += term.Int64()
+			continue
+		}
+
+		total.Add(total, term)
+	}
+
+	return total.Add(total, big.NewInt(sum))
+}
+
+// positionRange returns the smallest and the largest position.
+func positionRange(positions []int) (int, int) {
+	low, high := positions[0], positions[0]
+
+	for _, position := range positions {
+		if position < low {
+			low = position
+		}
+
+		if position > high {
+			high = position
+		}
+	}
+
+	return low, high
+}
+
+// weightsOf returns weights of a WeightedCost given as a value or a pointer.
+func weightsOf(cost CostFunction) ([]float64, bool) {
+	switch weighted := cost.(type) {
+	case WeightedCost:
+		return weighted.Weights, true
+	case *WeightedCost:
+		return weighted.Weights, true
+	}
+
+	return nil, false
+}
+
+// checkWeights checks that a weighted cost has a weight for every crab.
+func checkWeights(positions []int, cost CostFunction) error {
+	if weights, ok := weightsOf(cost); ok && len(weights) != len(positions) {
+		return errors.New(fmt.Sprintf("got %d weights for %d crabs", len(weights), len(positions)))
+	}
+
+	return nil
+}
+
+// Solve finds the smallest position with minimal total fuel. Fuel never decreases with distance, so the optimum lies
+// between the outermost crabs. Convex costs are minimized by ternary search, other costs by checking every position.
+// Fuel of an IntegerCost is summed and compared exactly, other costs are compared in floating point.
+func Solve(positions []int, cost CostFunction) (Alignment, error) {
+	if len(positions) == 0 {
+		return Alignment{}, errors.New("cannot align without crabs")
+	}
+
+	if err := checkWeights(positions, cost); err != nil {
+		return Alignment{}, err
+	}
+
+	low, high := positionRange(positions)
+
+	if integerCost, ok := cost.(IntegerCost); ok {
+		return minimizeBy(low, high, cost.Convex(), func(target int) Alignment {
+			return exactAlignment(target, ExactTotalFuel(positions, integerCost, target))
+		}), nil
+	}
+
+	return minimize(low, high, cost.Convex(), func(target int) float64 {
+		return TotalFuel(positions, cost, target)
+	}), nil
+}
+
+// minimize finds the smallest position in [low, high] with minimal fuel. Convex fuel functions are minimized by
+// ternary search, others by checking every position.
+func minimize(low, high int, convex bool, fuelAt func(target int) float64) Alignment {
+	return minimizeBy(low, high, convex, func(target int) Alignment {
+		return Alignment{Position: target, Fuel: fuelAt(target)}
+	})
+}
+
+// minimizeBy is minimize for alignments compared with compareFuel.
+func minimizeBy(low, high int, convex bool, alignAt func(target int) Alignment) Alignment {
+	if convex {
+		for high-low > 2 {
+			m1 := low + (high-low)/3
+			m2 := high - (high-low)/3
+
+			switch compareFuel(alignAt(m1), alignAt(m2)) {
+			case -1:
+				high = m2 - 1
+			case 1:
+				low = m1 + 1
+			default:
+				// Minimum of a convex function lies between two points with equal values, but the function may be flat
+				// to the left of m1, so only the right side is dropped to keep the smallest optimal position.
+				high = m2
+			}
+		}
+	}
+
+	best := alignAt(low)
+
+	for target := low + 1; target <= high; target++ {
+		if alignment := alignAt(target); compareFuel(alignment, best) < 0 {
+			best = alignment
+		}
+	}
+
+	return best
+}
@@ -2,7 +2,8 @@ package util
 
 import "sort"
 
-// ComputeMedian computes median of the values in the provided slice.
+// ComputeMedian computes median of the values in the provided slice. The middle value is returned for odd lengths and
+// the two middle values are averaged (rounding towards zero) for even lengths.
 func ComputeMedian(values []int) int {
 	valuesCpy := make([]int, len(values))
 	copy(valuesCpy, values)
@@ -10,7 +11,7 @@ func ComputeMedian(values []int) int {
 	sort.Ints(valuesCpy)
 	medianIdx := len(valuesCpy) / 2
 
-	if medianIdx%2 != 0 {
+	if len(valuesCpy)%2 != 0 {
 		return valuesCpy[medianIdx]
 	}
 
@@ -0,0 +1,117 @@
+package test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-7/internal/crab"
+)
+
+// bruteForceClusters tries every set of k positions in [0, maxPosition] with every crab moving to its cheapest one.
+func bruteForceClusters(positions []int, cost crab.CostFunction, k int, maxPosition int) float64 {
+	best := math.Inf(1)
+	centers := make([]int, k)
+
+	var search func(c int, from int)
+	search = func(c int, from int) {
+		if c == k {
+			fuel := 0.0
+
+			for i, position := range positions {
+				cheapest := math.Inf(1)
+
+				for _, center := range centers {
+					cheapest = math.Min(cheapest, cost.Fuel(i, abs(position-center)))
+				}
+
+				fuel += cheapest
+			}
+
+			best = math.Min(best, fuel)
+			return
+		}
+
+		for center := from; center <= maxPosition; center++ {
+			centers[c] = center
+			search(c+1, center)
+		}
+	}
+
+	search(0, 0)
+
+	return best
+}
+
+func abs(a int) int {
+	if a < 0 {
+		return -a
+	}
+
+	return a
+}
+
+func TestSolveClustersExample(t *testing.T) {
+	single, err := crab.SolveClusters(examplePositions, crab.LinearCost{}, 1)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	if single.Fuel != 37 || single.Clusters[0].Position != 2 || len(single.Clusters[0].Crabs) != 10 {
+		t.Errorf("expected single cluster at 2 with 37 fuel, actual %+v", single)
+	}
+
+	// Every crab gets its own position.
+	all, _ := crab.SolveClusters([]int{5, 1, 9}, crab.TriangularCost{}, 3)
+
+	if all.Fuel != 0 || all.Clusters[0].Position != 1 || all.Clusters[0].Crabs[0] != 1 || all.Clusters[2].Position != 9 {
+		t.Errorf("expected one cluster per crab, actual %+v", all)
+	}
+
+	if _, err := crab.SolveClusters(examplePositions, crab.LinearCost{}, 0); err == nil {
+		t.Errorf("expected error for 0 clusters, but none occurred")
+	}
+
+	if _, err := crab.SolveClusters(examplePositions, crab.LinearCost{}, 11); err == nil {
+		t.Errorf("expected error for more clusters than crabs, but none occurred")
+	}
+}
+
+func TestSolveClustersMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(4))
+
+	for round := 0; round < 40; round++ {
+		positions := make([]int, 2+rng.Intn(6))
+		weights := make([]float64, len(positions))
+
+		for i := range positions {
+			positions[i] = rng.Intn(16)
+			weights[i] = 0.5 + rng.Float64()*3
+		}
+
+		weighted, _ := crab.NewWeightedCost(crab.QuadraticCost{}, weights)
+		costs := []crab.CostFunction{crab.LinearCost{}, crab.TriangularCost{}, crab.PowerCost{P: 0.5}, weighted}
+
+		for _, cost := range costs {
+			for k := 1; k <= 3 && k <= len(positions); k++ {
+				clustering, err := crab.SolveClusters(positions, cost, k)
+
+				if err != nil {
+					t.Fatalf("encountered error (%s)", err.Error())
+				}
+
+				assigned := 0
+				for _, cluster := range clustering.Clusters {
+					assigned += len(cluster.Crabs)
+				}
+
+				expected := bruteForceClusters(positions, cost, k, 15)
+
+				if assigned != len(positions) || math.Abs(clustering.Fuel-expected) > 1e-9*math.Max(1, expected) {
+					t.Errorf("%T %v k=%d: expected fuel %g, actual %+v", cost, positions, k, expected, clustering)
+				}
+			}
+		}
+	}
+}
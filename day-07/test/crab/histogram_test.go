@@ -0,0 +1,241 @@
+package test
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-7/internal/crab"
+	"github.com/PrimozLavric/advent-of-code-2021/day-7/internal/util"
+)
+
+func TestHistogramMatchesSlice(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+
+	for round := 0; round < 50; round++ {
+		positions := make([]int, 1+rng.Intn(40))
+		records := make([]string, len(positions))
+
+		for i := range positions {
+			positions[i] = rng.Intn(300) - 150
+			records[i] = fmt.Sprint(positions[i])
+		}
+
+		h, err := crab.ReadHistogram(strings.NewReader(strings.Join(records, ",") + "\n"))
+
+		if err != nil {
+			t.Fatalf("encountered error (%s)", err.Error())
+		}
+
+		if h.Total != int64(len(positions)) || h.Median() != util.ComputeMedian(positions) {
+			t.Errorf("%v: expected median %d, actual %d", positions, util.ComputeMedian(positions), h.Median())
+		}
+
+		sum := 0
+		for _, position := range positions {
+			sum += position
+		}
+
+		if math.Abs(h.Mean()-float64(sum)/float64(len(positions))) > 1e-9 {
+			t.Errorf("%v: unexpected mean %g", positions, h.Mean())
+		}
+
+		costs := []crab.CostFunction{crab.LinearCost{}, crab.TriangularCost{}, crab.QuadraticCost{}, crab.PowerCost{P: 1.5}}
+
+		for _, cost := range costs {
+			curve, err := h.FuelCurve(cost)
+
+			if err != nil {
+				t.Fatalf("encountered error (%s)", err.Error())
+			}
+
+			low, high := positions[0], positions[0]
+			for _, position := range positions {
+				low, high = minInt(low, position), maxInt(high, position)
+			}
+
+			if h.Min != low || h.Max() != high || len(curve) != high-low+1 {
+				t.Fatalf("%v: expected range [%d, %d], actual [%d, %d]", positions, low, high, h.Min, h.Max())
+			}
+
+			for i, fuel := range curve {
+				if expected := crab.TotalFuel(positions, cost, low+i); math.Abs(fuel-expected) > 1e-6*math.Max(1, expected) {
+					t.Errorf("%T %v: expected fuel %g at %d, actual %g", cost, positions, expected, low+i, fuel)
+				}
+			}
+
+			expected, _ := crab.Solve(positions, cost)
+
+			if alignment, _ := h.Solve(cost); math.Abs(alignment.Fuel-expected.Fuel) > 1e-6*math.Max(1, expected.Fuel) {
+				t.Errorf("%T %v: expected %+v, actual %+v", cost, positions, expected, alignment)
+			}
+		}
+	}
+}
+
+func TestReadHistogramErrors(t *testing.T) {
+	h, err := crab.ReadHistogram(strings.NewReader("16,1,2,0,4,\n2,7, 1,2,14"))
+
+	if err != nil || h.Total != 10 || h.Median() != 2 {
+		t.Errorf("unexpected histogram %+v (%v)", h, err)
+	}
+
+	if alignment, _ := h.Solve(crab.TriangularCost{}); alignment.Position != 5 || alignment.Fuel != 168 {
+		t.Errorf("expected position 5 with 168 fuel, actual %+v", alignment)
+	}
+
+	testCases := map[string]string{
+		"":            "no crab positions",
+		"1,,2":        "record 2",
+		"1,2x":        "record 2 has unexpected character",
+		"1,--2":       "record 2 has unexpected character",
+		"0,999999999": "positions span more than",
+	}
+
+	for input, expected := range testCases {
+		if _, err := crab.ReadHistogram(strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), expected) {
+			t.Errorf("input %q: expected error containing '%s', actual %v", input, expected, err)
+		}
+	}
+
+	weighted, _ := crab.NewWeightedCost(crab.LinearCost{}, []float64{1})
+
+	if _, err := h.FuelCurve(weighted); err == nil {
+		t.Errorf("expected error for weighted cost, but none occurred")
+	}
+}
+
+func TestHistogramAddSpan(t *testing.T) {
+	// Spare capacity from growing the buffer does not count towards the span of positions.
+	h := crab.Histogram{}
+
+	for _, position := range []int{0, 1<<25 + 1, 1<<25 + 2, -1} {
+		if err := h.Add(position); err != nil {
+			t.Fatalf("adding %d: encountered error (%s)", position, err.Error())
+		}
+	}
+
+	h.Compact()
+
+	if h.Min != -1 || h.Max() != 1<<25+2 || h.Total != 4 || h.Counts[0] != 1 || h.Counts[len(h.Counts)-1] != 1 {
+		t.Errorf("unexpected histogram range [%d, %d] with %d crabs", h.Min, h.Max(), h.Total)
+	}
+
+	h = crab.Histogram{}
+
+	for _, position := range []int{5, 5 + crab.MaxHistogramRange - 1, 6} {
+		if err := h.Add(position); err != nil {
+			t.Fatalf("adding %d: encountered error (%s)", position, err.Error())
+		}
+	}
+
+	if err := h.Add(4); err == nil {
+		t.Errorf("expected error for positions spanning more than %d values", crab.MaxHistogramRange)
+	}
+}
+
+// bruteForceExact computes exact fuel at every position of the histogram.
+func bruteForceExact(h *crab.Histogram, cost crab.IntegerCost) []*big.Int {
+	curve := make([]*big.Int, len(h.Counts))
+	term := new(big.Int)
+
+	for t := range curve {
+		curve[t] = new(big.Int)
+
+		for p, count := range h.Counts {
+			cost.ExactFuel(0, abs(t-p), term)
+			curve[t].Add(curve[t], term.Mul(term, big.NewInt(count)))
+		}
+	}
+
+	return curve
+}
+
+func TestHistogramExactFuel(t *testing.T) {
+	rng := rand.New(rand.NewSource(48))
+	costs := []crab.IntegerCost{crab.LinearCost{}, crab.TriangularCost{}, crab.QuadraticCost{}}
+
+	for round := 0; round < 40; round++ {
+		h := crab.Histogram{Min: rng.Intn(100) - 50, Counts: make([]int64, 1+rng.Intn(50))}
+
+		// Large counts push fuel past 2^53 and, in later rounds, past int64 while the crab count still fits.
+		maxCount := int64(math.MaxInt64) / int64(len(h.Counts))
+
+		if round < 30 {
+			maxCount = int64(1) << (25 + round)
+		}
+
+		for i := range h.Counts {
+			if rng.Intn(3) == 0 || i == 0 || i == len(h.Counts)-1 {
+				h.Counts[i] = 1 + rng.Int63n(maxCount)
+				h.Total += h.Counts[i]
+			}
+		}
+
+		for _, cost := range costs {
+			expected := bruteForceExact(&h, cost)
+			curve, err := h.FuelCurve(cost)
+
+			if err != nil {
+				t.Fatalf("encountered error (%s)", err.Error())
+			}
+
+			best := 0
+
+			for i := range curve {
+				if expectedFloat, _ := new(big.Float).SetInt(expected[i]).Float64(); curve[i] != expectedFloat {
+					t.Errorf("%T round %d: expected fuel %s at offset %d, actual %g", cost, round, expected[i], i,
+						curve[i])
+				}
+
+				if expected[i].Cmp(expected[best]) < 0 {
+					best = i
+				}
+			}
+
+			alignment, err := h.Solve(cost)
+
+			if err != nil || alignment.Position != h.Min+best || alignment.Exact.Cmp(expected[best]) != 0 {
+				t.Errorf("%T round %d: expected position %d with %s fuel, actual %+v (%v)", cost, round, h.Min+best,
+					expected[best], alignment, err)
+			}
+		}
+	}
+}
+
+func TestHistogramGenericCostRange(t *testing.T) {
+	h := crab.Histogram{}
+	h.Add(0)
+	h.Add(crab.MaxGenericCurveRange)
+
+	if _, err := h.FuelCurve(crab.PowerCost{P: 1.5}); err == nil {
+		t.Errorf("expected error for generic cost over %d positions", crab.MaxGenericCurveRange+1)
+	}
+
+	if _, err := h.Solve(crab.PowerCost{P: 1.5}); err == nil {
+		t.Errorf("expected error for generic cost over %d positions", crab.MaxGenericCurveRange+1)
+	}
+
+	if alignment, err := h.Solve(crab.LinearCost{}); err != nil || alignment.Position != 0 {
+		t.Errorf("expected linear cost to be solved, actual %+v (%v)", alignment, err)
+	}
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+
+	return b
+}
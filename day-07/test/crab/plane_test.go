@@ -0,0 +1,89 @@
+package test
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-7/internal/crab"
+)
+
+func TestSolvePlaneMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(6))
+
+	for round := 0; round < 100; round++ {
+		points := make([]crab.Point, 1+rng.Intn(9))
+
+		for i := range points {
+			points[i] = crab.Point{X: rng.Intn(21) - 10, Y: rng.Intn(21) - 10}
+		}
+
+		for _, metric := range []crab.Metric{crab.Manhattan, crab.Euclidean} {
+			alignment, err := crab.SolvePlane(points, metric)
+
+			if err != nil {
+				t.Fatalf("encountered error (%s)", err.Error())
+			}
+
+			bestLattice := math.Inf(1)
+
+			for x := -10; x <= 10; x++ {
+				for y := -10; y <= 10; y++ {
+					bestLattice = math.Min(bestLattice, crab.PlaneFuel(points, metric, float64(x), float64(y)))
+				}
+			}
+
+			if math.Abs(alignment.LatticeFuel-bestLattice) > 1e-9 {
+				t.Errorf("metric %d %v: expected lattice fuel %g, actual %+v", metric, points, bestLattice, alignment)
+			}
+
+			if alignment.Fuel > alignment.LatticeFuel+1e-9 {
+				t.Errorf("metric %d %v: real optimum %g is worse than lattice %g", metric, points, alignment.Fuel,
+					alignment.LatticeFuel)
+			}
+
+			// No nearby real point is better than the real optimum.
+			for _, d := range [][2]float64{{0.01, 0}, {-0.01, 0}, {0, 0.01}, {0, -0.01}, {0.007, 0.007}} {
+				if fuel := crab.PlaneFuel(points, metric, alignment.X+d[0], alignment.Y+d[1]); fuel < alignment.Fuel-1e-6 {
+					t.Errorf("metric %d %v: (%g, %g) is not optimal", metric, points, alignment.X, alignment.Y)
+				}
+			}
+		}
+	}
+}
+
+func TestSolvePlaneSpecialCases(t *testing.T) {
+	// Three crabs at the origin outweigh the pull of the other two.
+	points := []crab.Point{{X: 0, Y: 0}, {X: 0, Y: 0}, {X: 0, Y: 0}, {X: 10, Y: 0}, {X: 0, Y: 10}}
+	alignment, _ := crab.SolvePlane(points, crab.Euclidean)
+
+	if math.Abs(alignment.X) > 1e-6 || math.Abs(alignment.Y) > 1e-6 || math.Abs(alignment.Fuel-20) > 1e-6 {
+		t.Errorf("expected optimum at origin with 20 fuel, actual %+v", alignment)
+	}
+
+	// Even number of crabs has a median range, reported by its center.
+	alignment, _ = crab.SolvePlane([]crab.Point{{X: 0, Y: 0}, {X: 4, Y: 2}}, crab.Manhattan)
+
+	if alignment.X != 2 || alignment.Y != 1 || alignment.Fuel != 6 || alignment.LatticeFuel != 6 {
+		t.Errorf("expected optimum (2, 1) with 6 fuel, actual %+v", alignment)
+	}
+
+	if _, err := crab.SolvePlane(nil, crab.Euclidean); err == nil {
+		t.Errorf("expected error without crabs, but none occurred")
+	}
+}
+
+func TestParsePoints(t *testing.T) {
+	points, err := crab.ParsePoints(strings.NewReader("1,2\n\n -3, 4 \n"))
+
+	if err != nil || len(points) != 2 || points[1] != (crab.Point{X: -3, Y: 4}) {
+		t.Errorf("unexpected points %v (%v)", points, err)
+	}
+
+	for _, input := range []string{"1,2\n3\n", "1,x\n", "1,2,3\n"} {
+		if _, err := crab.ParsePoints(strings.NewReader(input)); err == nil {
+			t.Errorf("expected error for input %q, but none occurred", input)
+		}
+	}
+}
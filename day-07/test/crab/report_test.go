@@ -0,0 +1,226 @@
+package test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-7/internal/crab"
+)
+
+func TestFuelProfileExample(t *testing.T) {
+	curve, err := crab.ComputeFuelProfile(examplePositions, crab.LinearCost{})
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	if curve.Min != 0 || len(curve.Fuel) != 17 {
+		t.Fatalf("expected curve over [0, 16], actual min %d with %d positions", curve.Min, len(curve.Fuel))
+	}
+
+	// Values from the puzzle statement.
+	expected := map[int]float64{1: 41, 2: 37, 3: 39, 10: 71}
+
+	for position, fuel := range expected {
+		if curve.Fuel[position] != fuel {
+			t.Errorf("position %d: expected %g, actual %g", position, fuel, curve.Fuel[position])
+		}
+	}
+
+	fuel, optimal := curve.Optimum()
+
+	if fuel != 37 || len(optimal) != 1 || optimal[0] != 2 {
+		t.Errorf("expected optimum 37 at [2], actual %g at %v", fuel, optimal)
+	}
+}
+
+func TestFuelProfileTies(t *testing.T) {
+	// With an even number of crabs every position between the two middle ones is optimal for linear cost.
+	curve, err := crab.ComputeFuelProfile([]int{0, 3, 7, 10}, crab.LinearCost{})
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	fuel, optimal := curve.Optimum()
+	expected := []int{3, 4, 5, 6, 7}
+
+	if fuel != 14 || len(optimal) != len(expected) {
+		t.Fatalf("expected optimum 14 at %v, actual %g at %v", expected, fuel, optimal)
+	}
+
+	for i := range expected {
+		if optimal[i] != expected[i] {
+			t.Errorf("expected optimum at %v, actual %v", expected, optimal)
+		}
+	}
+}
+
+func TestFuelProfileExactTies(t *testing.T) {
+	// Fuel at positions 0, 1 and 2 is 2e12, 2e12+1 and 2e12+2, so only position 0 is optimal even though the values
+	// are within a relative tolerance of 1e-12.
+	const far = 4000000
+	positions := make([]int, 0, 1000001)
+
+	for i := 0; i < 500001; i++ {
+		positions = append(positions, 0)
+	}
+
+	for i := 0; i < 500000; i++ {
+		positions = append(positions, far)
+	}
+
+	profile, err := crab.ComputeFuelProfile(positions, crab.LinearCost{})
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	fuel, optimal := profile.Optimum()
+	alignment, _ := crab.Solve(positions, crab.LinearCost{})
+
+	if fuel != 2e12 || len(optimal) != 1 || optimal[0] != 0 || alignment.Position != optimal[0] {
+		t.Errorf("expected optimum 2e12 at [0] matching %+v, actual %g at %v", alignment, fuel, optimal)
+	}
+
+	// Costs without a sweep are compared exactly as well.
+	generic, _ := crab.ComputeFuelProfile([]int{0, 0, 3, 3}, linearIntegerCost{})
+
+	if _, optimal := generic.Optimum(); len(optimal) != 4 || optimal[0] != 0 || optimal[3] != 3 {
+		t.Errorf("expected optimum at [0 1 2 3], actual %v", optimal)
+	}
+}
+
+// linearIntegerCost is LinearCost that is not recognized by the histogram sweep.
+type linearIntegerCost struct {
+	crab.LinearCost
+}
+
+func TestFuelProfileMatchesHistogram(t *testing.T) {
+	histogram, err := crab.ReadHistogram(strings.NewReader("16,1,2,0,4,2,7,1,2,14"))
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	weights := make([]float64, len(examplePositions))
+
+	for i := range weights {
+		weights[i] = 1
+	}
+
+	weighted, _ := crab.NewWeightedCost(crab.PowerCost{P: 1.5}, weights)
+
+	// Unit weights force the per-crab evaluation, which must agree with the histogram curve of the unweighted cost.
+	testCases := []struct {
+		cost       crab.CostFunction
+		unweighted crab.CostFunction
+	}{
+		{crab.LinearCost{}, crab.LinearCost{}},
+		{crab.TriangularCost{}, crab.TriangularCost{}},
+		{crab.PowerCost{P: 1.5}, crab.PowerCost{P: 1.5}},
+		{weighted, crab.PowerCost{P: 1.5}},
+	}
+
+	for _, testCase := range testCases {
+		cost := testCase.cost
+		curve, err := crab.ComputeFuelProfile(examplePositions, cost)
+
+		if err != nil {
+			t.Fatalf("encountered error (%s)", err.Error())
+		}
+
+		expected, _ := histogram.FuelCurve(testCase.unweighted)
+
+		for i := range expected {
+			if diff := curve.Fuel[i] - expected[i]; diff > 1e-6 || diff < -1e-6 {
+				t.Errorf("%T position %d: expected %g, actual %g", cost, curve.Min+i, expected[i], curve.Fuel[i])
+			}
+		}
+	}
+}
+
+func TestFuelProfileCSV(t *testing.T) {
+	curve, _ := crab.ComputeFuelProfile([]int{1, 3}, crab.TriangularCost{})
+
+	var buffer bytes.Buffer
+
+	if err := curve.WriteCSV(&buffer); err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	expected := "position,fuel\n1,3\n2,2\n3,3\n"
+
+	if buffer.String() != expected {
+		t.Errorf("expected %q, actual %q", expected, buffer.String())
+	}
+}
+
+func TestFuelProfilePlot(t *testing.T) {
+	curve, _ := crab.ComputeFuelProfile(examplePositions, crab.LinearCost{})
+
+	var buffer bytes.Buffer
+
+	if err := curve.Plot(&buffer, 17, 5); err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	lines := strings.Split(strings.TrimRight(buffer.String(), "\n"), "\n")
+
+	if len(lines) != 7 {
+		t.Fatalf("expected 5 rows, axis and labels, actual:\n%s", buffer.String())
+	}
+
+	bottom := lines[4]
+	axis := strings.Index(bottom, "|")
+
+	if strings.TrimSpace(bottom[:axis]) != "37" || strings.Count(bottom, "*") != 1 || bottom[axis+3] != '*' {
+		t.Errorf("expected optimum marked at column 2 of bottom row, actual:\n%s", buffer.String())
+	}
+
+	if err := curve.Plot(&buffer, 0, 5); err == nil {
+		t.Errorf("expected error for empty plot")
+	}
+}
+
+func TestFuelProfileWeightedRange(t *testing.T) {
+	weighted, _ := crab.NewWeightedCost(crab.LinearCost{}, []float64{1, 2})
+
+	if _, err := crab.ComputeFuelProfile([]int{0, crab.MaxGenericCurveRange}, weighted); err == nil {
+		t.Errorf("expected error for weighted cost over %d positions", crab.MaxGenericCurveRange+1)
+	}
+
+	if _, err := crab.ComputeFuelProfile([]int{math.MinInt64, math.MaxInt64}, weighted); err == nil {
+		t.Errorf("expected error for weighted cost over all positions")
+	}
+
+	profile, err := crab.ComputeFuelProfile([]int{0, crab.MaxGenericCurveRange - 1}, weighted)
+
+	if err != nil || len(profile.Fuel) != crab.MaxGenericCurveRange {
+		t.Errorf("expected profile of %d positions (%v)", crab.MaxGenericCurveRange, err)
+	}
+}
+
+func TestCrabFuel(t *testing.T) {
+	weighted, _ := crab.NewWeightedCost(crab.LinearCost{}, []float64{1, 2, 0.5})
+	fuel, err := crab.CrabFuel([]int{0, 5, 9}, weighted, 4)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	expected := []float64{4, 2, 2.5}
+
+	for i := range expected {
+		if fuel[i] != expected[i] {
+			t.Errorf("crab %d: expected %g, actual %g", i, expected[i], fuel[i])
+		}
+	}
+
+	// Weighted cost passed by value with too few weights is an error rather than a panic.
+	if _, err := crab.CrabFuel([]int{0, 5, 9, 12}, *weighted, 4); err == nil {
+		t.Errorf("expected error for mismatched weights, but none occurred")
+	}
+}
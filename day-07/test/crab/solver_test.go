@@ -0,0 +1,162 @@
+package test
+
+import (
+	"math"
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-7/internal/crab"
+)
+
+var examplePositions = []int{16, 1, 2, 0, 4, 2, 7, 1, 2, 14}
+
+// bruteForce checks every position in range [-10, 110].
+func bruteForce(positions []int, cost crab.CostFunction) float64 {
+	best := math.Inf(1)
+
+	for target := -10; target <= 110; target++ {
+		best = math.Min(best, crab.TotalFuel(positions, cost, target))
+	}
+
+	return best
+}
+
+func TestSolveExample(t *testing.T) {
+	testCases := map[string]crab.Alignment{
+		"linear":     {Position: 2, Fuel: 37},
+		"triangular": {Position: 5, Fuel: 168},
+		"quadratic":  {Position: 5, Fuel: 291},
+	}
+
+	for name, expected := range testCases {
+		cost, err := crab.ParseCostFunction(name)
+
+		if err != nil {
+			t.Fatalf("encountered error (%s)", err.Error())
+		}
+
+		alignment, err := crab.Solve(examplePositions, cost)
+
+		if err != nil || alignment.Position != expected.Position || alignment.Fuel != expected.Fuel ||
+			alignment.Exact == nil || alignment.Exact.Int64() != int64(expected.Fuel) {
+			t.Errorf("%s: expected %+v, actual %+v (%v)", name, expected, alignment, err)
+		}
+	}
+
+	// Floor of the mean (4) is not optimal for triangular cost.
+	positions := []int{0, 7, 7}
+
+	if alignment, _ := crab.Solve(positions, crab.TriangularCost{}); alignment.Position != 5 || alignment.Fuel != 21 {
+		t.Errorf("expected position 5 with 21 fuel, actual %+v", alignment)
+	}
+}
+
+func TestSolveMatchesBruteForce(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+
+	for round := 0; round < 200; round++ {
+		positions := make([]int, 1+rng.Intn(12))
+		weights := make([]float64, len(positions))
+
+		for i := range positions {
+			positions[i] = rng.Intn(100)
+			weights[i] = rng.Float64() * 5
+		}
+
+		weighted, err := crab.NewWeightedCost(crab.TriangularCost{}, weights)
+
+		if err != nil {
+			t.Fatalf("encountered error (%s)", err.Error())
+		}
+
+		costs := []crab.CostFunction{crab.LinearCost{}, crab.TriangularCost{}, crab.QuadraticCost{},
+			crab.PowerCost{P: 1.5}, crab.PowerCost{P: 0.5}, weighted}
+
+		for _, cost := range costs {
+			alignment, err := crab.Solve(positions, cost)
+
+			if err != nil {
+				t.Fatalf("encountered error (%s)", err.Error())
+			}
+
+			if expected := bruteForce(positions, cost); math.Abs(alignment.Fuel-expected) > 1e-6*math.Max(1, expected) {
+				t.Errorf("%T %v: expected fuel %g, actual %+v", cost, positions, expected, alignment)
+			}
+		}
+	}
+}
+
+func TestSolveExactLargeTotals(t *testing.T) {
+	// Totals exceed both 2^53, where float64 stops being exact, and int64.
+	positions := make([]int, 1000)
+
+	for i := range positions {
+		positions[i] = (i % 2) * 3000000000
+	}
+
+	expected := map[string]string{
+		// Linear fuel is flat between the crab groups, so the smallest optimal position 0 is chosen.
+		"linear": "1500000000000",
+		// Every crab moves 1500000000 steps.
+		"triangular": "1125000000750000000000",
+		"quadratic":  "2250000000000000000000",
+	}
+
+	for name, fuel := range expected {
+		cost, _ := crab.ParseCostFunction(name)
+		alignment, err := crab.Solve(positions, cost)
+
+		if err != nil || alignment.Exact == nil || alignment.Exact.String() != fuel || alignment.FuelString() != fuel {
+			t.Errorf("%s: expected %s fuel, actual %+v (%v)", name, fuel, alignment, err)
+		}
+	}
+
+	// One step away from the optimum differs by less than float64 resolution but is still compared exactly.
+	at := crab.ExactTotalFuel(positions, crab.QuadraticCost{}, 1500000000)
+	next := crab.ExactTotalFuel(positions, crab.QuadraticCost{}, 1500000001)
+	atFloat, _ := new(big.Float).SetInt(at).Float64()
+	nextFloat, _ := new(big.Float).SetInt(next).Float64()
+
+	if at.Cmp(next) >= 0 || atFloat != nextFloat {
+		t.Errorf("expected fuel %s at 1500000000 to be smaller than %s at 1500000001", at, next)
+	}
+}
+
+func TestSolveSmallestTiedPosition(t *testing.T) {
+	// Linear fuel is flat between the two middle crabs.
+	positions := []int{0, 0, 0, 1000, 1000, 1000}
+
+	for _, cost := range []crab.CostFunction{crab.LinearCost{}, crab.PowerCost{P: 1}} {
+		if alignment, _ := crab.Solve(positions, cost); alignment.Position != 0 || alignment.Fuel != 3000 {
+			t.Errorf("%T: expected smallest optimal position 0 with 3000 fuel, actual %+v", cost, alignment)
+		}
+	}
+}
+
+func TestSolveErrors(t *testing.T) {
+	if _, err := crab.Solve(nil, crab.LinearCost{}); err == nil {
+		t.Errorf("expected error without crabs, but none occurred")
+	}
+
+	weighted, _ := crab.NewWeightedCost(crab.LinearCost{}, []float64{1, 2})
+
+	if _, err := crab.Solve(examplePositions, weighted); err == nil {
+		t.Errorf("expected error for mismatched weights, but none occurred")
+	}
+
+	// Weighted cost passed by value is checked as well.
+	if _, err := crab.Solve(examplePositions, *weighted); err == nil {
+		t.Errorf("expected error for mismatched weights of a value cost, but none occurred")
+	}
+
+	if _, err := crab.NewWeightedCost(crab.LinearCost{}, []float64{1, -2}); err == nil {
+		t.Errorf("expected error for negative weight, but none occurred")
+	}
+
+	for _, name := range []string{"cubic", "power:x", "power:-1"} {
+		if _, err := crab.ParseCostFunction(name); err == nil {
+			t.Errorf("expected error for cost function %s, but none occurred", name)
+		}
+	}
+}
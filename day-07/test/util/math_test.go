@@ -0,0 +1,24 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-7/internal/util"
+)
+
+func TestComputeMedian(t *testing.T) {
+	// Parity of the length selects the case. Checking parity of the middle index instead averaged odd-length inputs
+	// such as {9, 1, 4, 7, 2} and took the upper middle value of even-length inputs such as {9, 1, 4, 6, 2, 8}.
+	testCases := map[int][]int{
+		3: {5, 1, 3},
+		4: {9, 1, 4, 7, 2},
+		5: {9, 1, 4, 6, 2, 8},
+		2: {16, 1, 2, 0, 4, 2, 7, 1, 2, 14},
+	}
+
+	for expected, values := range testCases {
+		if median := util.ComputeMedian(values); median != expected {
+			t.Errorf("%v: expected median %d, actual %d", values, expected, median)
+		}
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strconv"
 	"strings"
 
 	"github.com/PrimozLavric/advent-of-code-2021/day-8/internal/decoder"
@@ -121,8 +122,99 @@ func (app *application) decodeAndPrintPartTwo(entries []*entry) {
 	fmt.Printf("Sum of all decoded outputs is: %d\n", sum)
 }
 
+// loadDisplay returns a built-in display (seven, fourteen or sixteen) or reads display definition from a file.
+func (app *application) loadDisplay(nameOrPath string) (*decoder.Display, error) {
+	switch nameOrPath {
+	case "seven":
+		return decoder.SevenSegmentDigits, nil
+	case "fourteen":
+		return decoder.FourteenSegmentAlphanumeric, nil
+	case "sixteen":
+		return decoder.SixteenSegmentAlphanumeric, nil
+	}
+
+	file, err := os.Open(nameOrPath)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// Defer close the file.
+	defer func() {
+		err = file.Close()
+
+		if err != nil {
+			app.log.Printf("Failed to close file: %s\n", nameOrPath)
+		}
+	}()
+
+	return decoder.ParseDisplay(file)
+}
+
+// decodeAndPrintWithDisplay deduces symbols shown by the patterns of every entry for the provided display and prints
+// number of output symbols with unique segment count and decoded outputs. Outputs are summed if they are all numbers.
+func (app *application) decodeAndPrintWithDisplay(entries []*entry, display *decoder.Display) {
+	unique := make(map[string]bool)
+
+	for _, name := range display.UniqueSizeSymbols() {
+		unique[name] = true
+	}
+
+	uniqueCounter := 0
+	sum := 0
+	numeric := true
+
+	var outputs []string
+
+	for i, e := range entries {
+		sd, err := decoder.NewSymbolDecoder(display, append(append([]string{}, e.encodedDigits...), e.encodedOutput...))
+
+		if err != nil {
+			app.log.Fatalf("Decoding of entry %d failed (%s)", i+1, err.Error())
+		}
+
+		output := ""
+
+		for _, encodedSymbol := range e.encodedOutput {
+			symbol, err := sd.Decode(encodedSymbol)
+
+			if err != nil {
+				app.log.Fatalf("Decoding of entry %d failed (%s)", i+1, err.Error())
+			}
+
+			if unique[symbol] {
+				uniqueCounter++
+			}
+
+			output += symbol
+		}
+
+		if value, err := strconv.Atoi(output); err == nil {
+			sum += value
+		} else {
+			numeric = false
+		}
+
+		outputs = append(outputs, output)
+	}
+
+	fmt.Printf("Found %d symbols with unique segment count\n", uniqueCounter)
+
+	if numeric {
+		fmt.Printf("Sum of all decoded outputs is: %d\n", sum)
+		return
+	}
+
+	for _, output := range outputs {
+		fmt.Println(output)
+	}
+}
+
 func main() {
 	var encodedDigitsFile = flag.String("file", "input.txt", "Encoded digits file.")
+	var displayDefinition = flag.String("display", "",
+		"Decode with the generic decoder for a built-in display (seven, fourteen or sixteen) or a display definition "+
+			"file with one symbol and its segments per line (empty uses the digit decoder).")
 	flag.Parse()
 
 	app := application{log: log.Default()}
@@ -133,6 +225,17 @@ func main() {
 		app.log.Fatalf("Encountered error during signal patterns file parsing (%s).", err.Error())
 	}
 
+	if *displayDefinition != "" {
+		display, err := app.loadDisplay(*displayDefinition)
+
+		if err != nil {
+			app.log.Fatalf("Encountered error while loading display definition (%s).", err.Error())
+		}
+
+		app.decodeAndPrintWithDisplay(entries, display)
+		return
+	}
+
 	app.decodeAndPrintPartOne(entries)
 	app.decodeAndPrintPartTwo(entries)
 }
@@ -0,0 +1,209 @@
+package decoder
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"strings"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-8/internal/util"
+)
+
+// MaxSegments largest number of segments a Display may have.
+const MaxSegments = 64
+
+// Symbol is a named glyph that lights the listed segments.
+type Symbol struct {
+	Name     string
+	Segments string
+}
+
+// Display defines the segments of a display and the symbols it can show.
+type Display struct {
+	// Segments contains every segment name exactly once, the position of a segment is used as its index.
+	Segments string
+	Symbols  []Symbol
+	// segmentIndex maps segment name to its index.
+	segmentIndex map[rune]int
+	// masks holds segment set of each symbol as a bitmask of segment indices.
+	masks []uint64
+	// symbolByMask maps segment set to symbol index.
+	symbolByMask map[uint64]int
+}
+
+// SevenSegmentDigits standard seven segment display of decimal digits. Segment a is on top, b and c are on the right,
+// d is on the bottom, e and f are on the left and g is in the middle.
+var SevenSegmentDigits = mustDisplay("abcdefg", []Symbol{
+	{"0", "abcefg"}, {"1", "cf"}, {"2", "acdeg"}, {"3", "acdfg"}, {"4", "bcdf"},
+	{"5", "abdfg"}, {"6", "abdefg"}, {"7", "acf"}, {"8", "abcdefg"}, {"9", "abcdfg"},
+})
+
+// FourteenSegmentAlphanumeric fourteen segment display of digits and upper case letters. Segments a to f are placed
+// as on the seven segment display, g and h are left and right half of the middle bar, i, j and k are upper left
+// diagonal, upper vertical and upper right diagonal, l, m and n are lower left diagonal, lower vertical and lower right
+// diagonal.
+var FourteenSegmentAlphanumeric = mustDisplay("abcdefghijklmn", []Symbol{
+	{"0", "abcdefkl"}, {"1", "bck"}, {"2", "abdegh"}, {"3", "abcdh"}, {"4", "bcfgh"},
+	{"5", "adfgn"}, {"6", "acdefgh"}, {"7", "abc"}, {"8", "abcdefgh"}, {"9", "abcdfgh"},
+	{"A", "abcefgh"}, {"B", "abcdhjm"}, {"C", "adef"}, {"D", "abcdjm"}, {"E", "adefg"},
+	{"F", "aefg"}, {"G", "acdefh"}, {"H", "bcefgh"}, {"I", "adjm"}, {"J", "bcde"},
+	{"K", "efgkn"}, {"L", "def"}, {"M", "bcefik"}, {"N", "bcefin"}, {"O", "abcdef"},
+	{"P", "abefgh"}, {"Q", "abcdefn"}, {"R", "abefghn"}, {"S", "acdfgh"}, {"T", "ajm"},
+	{"U", "bcdef"}, {"V", "efkl"}, {"W", "bcefln"}, {"X", "ikln"}, {"Y", "ikm"},
+	{"Z", "adkl"},
+})
+
+// SixteenSegmentAlphanumeric sixteen segment display of digits, upper case letters and lower case c. Segments a and b
+// are left and right half of the top bar, c and d are on the right, e and f are right and left half of the bottom bar,
+// g and h are on the left, i and j are left and right half of the middle bar, k, l and m are upper left diagonal,
+// upper vertical and upper right diagonal, n, o and p are lower left diagonal, lower vertical and lower right diagonal.
+var SixteenSegmentAlphanumeric = mustDisplay("abcdefghijklmnop", []Symbol{
+	{"0", "abcdefghmn"}, {"1", "aeflo"}, {"2", "abcefgij"}, {"3", "abcdefj"}, {"4", "cdhij"},
+	{"5", "abefhip"}, {"6", "abdefghij"}, {"7", "abcd"}, {"8", "abcdefghij"}, {"9", "abcdefhij"},
+	{"A", "abcdghij"}, {"B", "abcdefjlo"}, {"C", "abefgh"}, {"D", "abcdeflo"}, {"E", "abefghi"},
+	{"F", "abghi"}, {"G", "abdefghj"}, {"H", "cdghij"}, {"I", "abeflo"}, {"J", "cdefg"},
+	{"K", "ghimp"}, {"L", "efgh"}, {"M", "cdghkm"}, {"N", "cdghkp"}, {"O", "abcdefgh"},
+	{"P", "abcghij"}, {"Q", "abcdefghp"}, {"R", "abcghijp"}, {"S", "abdefhij"}, {"T", "ablo"},
+	{"U", "cdefgh"}, {"V", "ghmn"}, {"W", "cdghnp"}, {"X", "kmnp"}, {"Y", "kmo"},
+	{"Z", "abefmn"}, {"c", "fgi"},
+})
+
+// NewDisplay creates a Display with the provided segment names and symbols. Symbols must have unique names and unique
+// segment sets.
+func NewDisplay(segments string, symbols []Symbol) (*Display, error) {
+	segmentRunes := []rune(segments)
+
+	if len(segmentRunes) == 0 || len(segmentRunes) > MaxSegments {
+		return nil, errors.New(fmt.Sprintf("display must have between 1 and %d segments, but got %d", MaxSegments,
+			len(segmentRunes)))
+	}
+
+	display := Display{Segments: segments, Symbols: symbols, segmentIndex: make(map[rune]int),
+		masks: make([]uint64, len(symbols)), symbolByMask: make(map[uint64]int)}
+
+	for i, segment := range segmentRunes {
+		if _, ok := display.segmentIndex[segment]; ok {
+			return nil, errors.New(fmt.Sprintf("segment %q is defined more than once", segment))
+		}
+
+		display.segmentIndex[segment] = i
+	}
+
+	names := make(map[string]bool)
+
+	for i, symbol := range symbols {
+		if names[symbol.Name] {
+			return nil, errors.New(fmt.Sprintf("symbol %q is defined more than once", symbol.Name))
+		}
+
+		names[symbol.Name] = true
+		mask, err := display.mask(symbol.Segments)
+
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("symbol %q: %s", symbol.Name, err.Error()))
+		}
+
+		if other, ok := display.symbolByMask[mask]; ok {
+			return nil, errors.New(fmt.Sprintf("symbols %q and %q light the same segments", symbols[other].Name,
+				symbol.Name))
+		}
+
+		display.masks[i] = mask
+		display.symbolByMask[mask] = i
+	}
+
+	return &display, nil
+}
+
+// mustDisplay creates a built-in Display and panics if its definition is invalid.
+func mustDisplay(segments string, symbols []Symbol) *Display {
+	display, err := NewDisplay(segments, symbols)
+
+	if err != nil {
+		panic(err)
+	}
+
+	return display
+}
+
+// ParseDisplay reads a display definition with one symbol per line, given as symbol name followed by the segments it
+// lights, for example "7 acf". Empty lines and lines starting with '#' are skipped. Display segments are all segments
+// used by the symbols in sorted order.
+func ParseDisplay(r io.Reader) (*Display, error) {
+	scanner := bufio.NewScanner(r)
+
+	var symbols []Symbol
+	used := ""
+
+	for line := 1; scanner.Scan(); line++ {
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+
+		if len(fields) != 2 {
+			return nil, errors.New(fmt.Sprintf("bad display definition at line %d, expected symbol and segments", line))
+		}
+
+		symbols = append(symbols, Symbol{Name: fields[0], Segments: fields[1]})
+
+		for _, segment := range fields[1] {
+			if !strings.ContainsRune(used, segment) {
+				used += string(segment)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(symbols) == 0 {
+		return nil, errors.New("display definition contains no symbols")
+	}
+
+	return NewDisplay(util.StringSort(used), symbols)
+}
+
+// mask converts segment names to a bitmask of segment indices.
+func (d *Display) mask(segments string) (uint64, error) {
+	var mask uint64
+
+	for _, segment := range segments {
+		idx, ok := d.segmentIndex[segment]
+
+		if !ok {
+			return 0, errors.New(fmt.Sprintf("unknown segment %q", segment))
+		}
+
+		if mask&(1<<idx) != 0 {
+			return 0, errors.New(fmt.Sprintf("segment %q is listed more than once", segment))
+		}
+
+		mask |= 1 << idx
+	}
+
+	return mask, nil
+}
+
+// UniqueSizeSymbols returns names of symbols that are the only ones lighting their number of segments.
+func (d *Display) UniqueSizeSymbols() []string {
+	sizes := make(map[int]int)
+
+	for _, mask := range d.masks {
+		sizes[bits.OnesCount64(mask)]++
+	}
+
+	var names []string
+
+	for i, mask := range d.masks {
+		if sizes[bits.OnesCount64(mask)] == 1 {
+			names = append(names, d.Symbols[i].Name)
+		}
+	}
+
+	return names
+}
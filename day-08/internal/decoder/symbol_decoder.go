@@ -0,0 +1,441 @@
+package decoder
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// SymbolDecoder decodes patterns of a display whose wires are connected to its segments in unknown order. Wires are
+// named the same as the display segments.
+type SymbolDecoder struct {
+	display *Display
+	// symbols maps every observed pattern to indices of the symbols it shows under some consistent wiring.
+	symbols map[uint64][]int
+	// wiring[w] is index of the segment connected to wire w, nil if more than one wiring is consistent with the
+	// observed patterns.
+	wiring []int
+}
+
+// wiringState holds remaining possibilities during the wiring search.
+type wiringState struct {
+	// domains[w] bitmask of segments wire w may be connected to.
+	domains []uint64
+	// candidates[p] indices of symbols that pattern p may show.
+	candidates [][]int
+}
+
+// wiringSolver searches for assignments of symbols to the observed patterns under which some wiring lights exactly the
+// observed patterns.
+type wiringSolver struct {
+	display  *Display
+	patterns []uint64
+	all      uint64
+	// possible[p][symbol] reports whether pattern p shows the symbol under some consistent wiring.
+	possible [][]bool
+	// domains of the first consistent assignment.
+	domains []uint64
+}
+
+// NewSymbolDecoder deduces wiring from the observed patterns and creates a SymbolDecoder. Every pattern must show a
+// symbol of the display and distinct patterns show distinct symbols. Symbols of the patterns are deduced by constraint
+// propagation over possible segments of every wire and possible symbols of every pattern, branching on the pattern
+// with the fewest options once propagation stalls. A complete assignment of symbols is consistent if wires can be
+// matched to segments so that every pattern lights exactly the segments of its symbol.
+//
+// All symbols a pattern may show are collected, so patterns that show the same symbol under every consistent wiring
+// are decoded even if the wiring itself is not unique, which is common when only some symbols of a display are
+// observed. An error is returned only if no wiring is consistent with the patterns, ambiguous patterns fail to Decode.
+func NewSymbolDecoder(display *Display, patterns []string) (*SymbolDecoder, error) {
+	segmentCount := len(display.segmentIndex)
+	solver := wiringSolver{display: display, all: ^uint64(0) >> (MaxSegments - segmentCount)}
+	state := wiringState{domains: make([]uint64, segmentCount)}
+	seen := make(map[uint64]bool)
+
+	for _, pattern := range patterns {
+		mask, err := display.mask(pattern)
+
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("pattern %q: %s", pattern, err.Error()))
+		}
+
+		if seen[mask] {
+			continue
+		}
+
+		seen[mask] = true
+		solver.patterns = append(solver.patterns, mask)
+		solver.possible = append(solver.possible, make([]bool, len(display.masks)))
+
+		var candidates []int
+
+		for i, symbolMask := range display.masks {
+			if bits.OnesCount64(symbolMask) == bits.OnesCount64(mask) {
+				candidates = append(candidates, i)
+			}
+		}
+
+		state.candidates = append(state.candidates, candidates)
+	}
+
+	for w := range state.domains {
+		state.domains[w] = solver.all
+	}
+
+	solver.search(&state)
+
+	if solver.domains == nil {
+		return nil, errors.New("no wiring of display segments is consistent with the observed patterns")
+	}
+
+	sd := SymbolDecoder{display: display, symbols: make(map[uint64][]int)}
+	uniqueSymbols := true
+
+	for p, pattern := range solver.patterns {
+		for symbol, possible := range solver.possible[p] {
+			if possible {
+				sd.symbols[pattern] = append(sd.symbols[pattern], symbol)
+			}
+		}
+
+		uniqueSymbols = uniqueSymbols && len(sd.symbols[pattern]) == 1
+	}
+
+	// With a single assignment of symbols, wiring is unique if its matching is.
+	if wiring := perfectMatching(solver.domains); uniqueSymbols && hasUniqueMatching(solver.domains, wiring) {
+		sd.wiring = wiring
+	}
+
+	return &sd, nil
+}
+
+// clone returns a deep copy of the state.
+func (s *wiringState) clone() *wiringState {
+	c := wiringState{domains: make([]uint64, len(s.domains)), candidates: make([][]int, len(s.candidates))}
+	copy(c.domains, s.domains)
+
+	for i, candidates := range s.candidates {
+		c.candidates[i] = append([]int(nil), candidates...)
+	}
+
+	return &c
+}
+
+// search propagates constraints of the state and branches until every pattern has a single symbol. States whose
+// candidates are all known to be possible are skipped, they cannot reveal another symbol of any pattern.
+func (s *wiringSolver) search(state *wiringState) {
+	if !s.propagate(state) || s.known(state) {
+		return
+	}
+
+	branchPattern := -1
+
+	for p, candidates := range state.candidates {
+		count := len(candidates)
+
+		if count > 1 && (branchPattern < 0 || count < len(state.candidates[branchPattern])) {
+			branchPattern = p
+		}
+	}
+
+	if branchPattern < 0 {
+		if !hasPerfectMatching(state.domains) {
+			return
+		}
+
+		for p, candidates := range state.candidates {
+			s.possible[p][candidates[0]] = true
+		}
+
+		if s.domains == nil {
+			s.domains = state.domains
+		}
+
+		return
+	}
+
+	for _, symbol := range state.candidates[branchPattern] {
+		child := state.clone()
+		child.candidates[branchPattern] = []int{symbol}
+		s.search(child)
+	}
+}
+
+// known reports whether every candidate symbol of every pattern of the state is already known to be possible.
+func (s *wiringSolver) known(state *wiringState) bool {
+	for p, candidates := range state.candidates {
+		for _, symbol := range candidates {
+			if !s.possible[p][symbol] {
+				return false
+			}
+		}
+	}
+
+	return s.domains != nil
+}
+
+// propagate removes impossible segments and symbols until nothing changes. It returns false if the state has no
+// solution.
+func (s *wiringSolver) propagate(state *wiringState) bool {
+	for changed := true; changed; {
+		changed = false
+
+		// Keep only symbols that a pattern can show under some wiring permitted by the domains.
+		for p, pattern := range s.patterns {
+			kept := state.candidates[p][:0]
+
+			for _, symbol := range state.candidates[p] {
+				if s.canShow(state.domains, pattern, s.display.masks[symbol]) {
+					kept = append(kept, symbol)
+				}
+			}
+
+			if len(kept) == 0 {
+				return false
+			}
+
+			changed = changed || len(kept) != len(state.candidates[p])
+			state.candidates[p] = kept
+		}
+
+		// Distinct patterns show distinct symbols.
+		for p, candidates := range state.candidates {
+			if len(candidates) != 1 {
+				continue
+			}
+
+			for q := range state.candidates {
+				if q == p {
+					continue
+				}
+
+				kept := state.candidates[q][:0]
+
+				for _, symbol := range state.candidates[q] {
+					if symbol != candidates[0] {
+						kept = append(kept, symbol)
+					}
+				}
+
+				if len(kept) == 0 {
+					return false
+				}
+
+				changed = changed || len(kept) != len(state.candidates[q])
+				state.candidates[q] = kept
+			}
+		}
+
+		// A wire lit by a pattern connects to a segment lit by one of its symbols and vice versa.
+		for w := range state.domains {
+			domain := state.domains[w]
+
+			for p, pattern := range s.patterns {
+				var allowed uint64
+
+				for _, symbol := range state.candidates[p] {
+					if pattern&(1<<w) != 0 {
+						allowed |= s.display.masks[symbol]
+					} else {
+						allowed |= s.all &^ s.display.masks[symbol]
+					}
+				}
+
+				domain &= allowed
+			}
+
+			if domain == 0 {
+				return false
+			}
+
+			changed = changed || domain != state.domains[w]
+			state.domains[w] = domain
+		}
+
+		// Every segment is connected to exactly one wire.
+		for w, domain := range state.domains {
+			if bits.OnesCount64(domain) != 1 {
+				continue
+			}
+
+			for v := range state.domains {
+				if v != w && state.domains[v]&domain != 0 {
+					state.domains[v] &^= domain
+
+					if state.domains[v] == 0 {
+						return false
+					}
+
+					changed = true
+				}
+			}
+		}
+
+		for segment := 0; segment < len(state.domains); segment++ {
+			wire, count := -1, 0
+
+			for w, domain := range state.domains {
+				if domain&(1<<segment) != 0 {
+					wire = w
+					count++
+				}
+			}
+
+			if count == 0 {
+				return false
+			}
+
+			if count == 1 && state.domains[wire] != 1<<segment {
+				state.domains[wire] = 1 << segment
+				changed = true
+			}
+		}
+	}
+
+	return true
+}
+
+// canShow checks if pattern of wires can show the symbol with the provided segments under some wiring permitted by
+// the domains, that is if wires can be matched to segments so that exactly the lit wires connect to lit segments.
+func (s *wiringSolver) canShow(domains []uint64, pattern uint64, symbol uint64) bool {
+	restricted := make([]uint64, len(domains))
+
+	for w, domain := range domains {
+		if pattern&(1<<w) != 0 {
+			restricted[w] = domain & symbol
+		} else {
+			restricted[w] = domain &^ symbol
+		}
+
+		if restricted[w] == 0 {
+			return false
+		}
+	}
+
+	return hasPerfectMatching(restricted)
+}
+
+// hasPerfectMatching checks if every wire can be connected to a distinct segment of its domain.
+func hasPerfectMatching(domains []uint64) bool {
+	return perfectMatching(domains) != nil
+}
+
+// perfectMatching connects every wire to a distinct segment of its domain. It returns the segment of every wire or
+// nil if there is no such matching.
+func perfectMatching(domains []uint64) []int {
+	// wireOf[segment] is the matched wire plus one, zero if segment is unmatched.
+	wireOf := make([]int, MaxSegments)
+
+	var augment func(w int, visited *uint64) bool
+	augment = func(w int, visited *uint64) bool {
+		for domain := domains[w] &^ *visited; domain != 0; domain &= domain - 1 {
+			segment := bits.TrailingZeros64(domain)
+			*visited |= 1 << segment
+
+			if wireOf[segment] == 0 || augment(wireOf[segment]-1, visited) {
+				wireOf[segment] = w + 1
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for w := range domains {
+		var visited uint64
+
+		if !augment(w, &visited) {
+			return nil
+		}
+	}
+
+	matching := make([]int, len(domains))
+
+	for segment, wire := range wireOf {
+		if wire != 0 {
+			matching[wire-1] = segment
+		}
+	}
+
+	return matching
+}
+
+// hasUniqueMatching checks if matching is the only perfect matching of the domains, that is if no matched segment can
+// be replaced.
+func hasUniqueMatching(domains []uint64, matching []int) bool {
+	if matching == nil {
+		return false
+	}
+
+	restricted := make([]uint64, len(domains))
+
+	for w, segment := range matching {
+		copy(restricted, domains)
+		restricted[w] &^= 1 << segment
+
+		if hasPerfectMatching(restricted) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Wiring returns the deduced mapping from wire names to segment names. It returns nil if more than one wiring is
+// consistent with the observed patterns.
+func (sd *SymbolDecoder) Wiring() map[rune]rune {
+	if sd.wiring == nil {
+		return nil
+	}
+
+	segments := []rune(sd.display.Segments)
+	wiring := make(map[rune]rune)
+
+	for w, segment := range sd.wiring {
+		wiring[segments[w]] = segments[segment]
+	}
+
+	return wiring
+}
+
+// Decode returns name of the symbol shown by the provided pattern of wires. Observed patterns are decoded if they show
+// the same symbol under every consistent wiring, other patterns only if the wiring is unique.
+func (sd *SymbolDecoder) Decode(pattern string) (string, error) {
+	wires, err := sd.display.mask(pattern)
+
+	if err != nil {
+		return "", errors.New(fmt.Sprintf("pattern %q: %s", pattern, err.Error()))
+	}
+
+	if symbols, ok := sd.symbols[wires]; ok {
+		if len(symbols) > 1 {
+			names := make([]string, len(symbols))
+
+			for i, symbol := range symbols {
+				names[i] = sd.display.Symbols[symbol].Name
+			}
+
+			return "", errors.New(fmt.Sprintf("pattern %q is ambiguous, it may show any of %v", pattern, names))
+		}
+
+		return sd.display.Symbols[symbols[0]].Name, nil
+	}
+
+	if sd.wiring == nil {
+		return "", errors.New(fmt.Sprintf("pattern %q was not observed and the wiring is not unique", pattern))
+	}
+
+	var segments uint64
+
+	for ; wires != 0; wires &= wires - 1 {
+		segments |= 1 << sd.wiring[bits.TrailingZeros64(wires)]
+	}
+
+	symbol, ok := sd.display.symbolByMask[segments]
+
+	if !ok {
+		return "", errors.New(fmt.Sprintf("pattern %q does not show any symbol", pattern))
+	}
+
+	return sd.display.Symbols[symbol].Name, nil
+}
@@ -0,0 +1,276 @@
+package test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/PrimozLavric/advent-of-code-2021/day-8/internal/decoder"
+)
+
+// scramble connects display segments to wires in random order and returns patterns of all symbols in random order
+// together with the wire connected to each segment.
+func scramble(rng *rand.Rand, display *decoder.Display) ([]string, []string, map[rune]rune) {
+	segments := []rune(display.Segments)
+	wires := make([]rune, len(segments))
+	copy(wires, segments)
+	rng.Shuffle(len(wires), func(i, j int) { wires[i], wires[j] = wires[j], wires[i] })
+
+	wireOf := make(map[rune]rune)
+	for i, segment := range segments {
+		wireOf[segment] = wires[i]
+	}
+
+	order := rng.Perm(len(display.Symbols))
+	patterns := make([]string, len(order))
+	names := make([]string, len(order))
+
+	for i, symbolIdx := range order {
+		var pattern strings.Builder
+
+		for _, segment := range display.Symbols[symbolIdx].Segments {
+			pattern.WriteRune(wireOf[segment])
+		}
+
+		patterns[i] = pattern.String()
+		names[i] = display.Symbols[symbolIdx].Name
+	}
+
+	return patterns, names, wireOf
+}
+
+func TestSymbolDecoderExample(t *testing.T) {
+	patterns := strings.Fields("acedgfb cdfbe gcdfa fbcad dab cefabd cdfgeb eafb cagedb ab")
+	sd, err := decoder.NewSymbolDecoder(decoder.SevenSegmentDigits, patterns)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	decoded := ""
+
+	for _, pattern := range strings.Fields("cdfeb fcadb cdfeb cdbaf") {
+		symbol, err := sd.Decode(pattern)
+
+		if err != nil {
+			t.Fatalf("encountered error (%s)", err.Error())
+		}
+
+		decoded += symbol
+	}
+
+	if decoded != "5353" {
+		t.Errorf("expected 5353, actual %s", decoded)
+	}
+
+	expected := map[rune]rune{'d': 'a', 'e': 'b', 'a': 'c', 'f': 'd', 'g': 'e', 'b': 'f', 'c': 'g'}
+
+	for wire, segment := range sd.Wiring() {
+		if expected[wire] != segment {
+			t.Errorf("wire %c: expected segment %c, actual %c", wire, expected[wire], segment)
+		}
+	}
+}
+
+func TestSymbolDecoderBuiltInDisplays(t *testing.T) {
+	rng := rand.New(rand.NewSource(8))
+	displays := map[string]*decoder.Display{
+		"seven":    decoder.SevenSegmentDigits,
+		"fourteen": decoder.FourteenSegmentAlphanumeric,
+		"sixteen":  decoder.SixteenSegmentAlphanumeric,
+	}
+
+	for name, display := range displays {
+		for trial := 0; trial < 20; trial++ {
+			patterns, names, wireOf := scramble(rng, display)
+			sd, err := decoder.NewSymbolDecoder(display, patterns)
+
+			if err != nil {
+				t.Fatalf("%s: encountered error (%s)", name, err.Error())
+			}
+
+			for segment, wire := range wireOf {
+				if sd.Wiring()[wire] != segment {
+					t.Errorf("%s: wire %c expected segment %c, actual %c", name, wire, segment, sd.Wiring()[wire])
+				}
+			}
+
+			for i, pattern := range patterns {
+				if symbol, err := sd.Decode(pattern); err != nil || symbol != names[i] {
+					t.Errorf("%s: pattern %s expected %s, actual %s (%v)", name, pattern, names[i], symbol, err)
+				}
+			}
+		}
+	}
+}
+
+func TestSymbolDecoderMatchesDigitDecoder(t *testing.T) {
+	rng := rand.New(rand.NewSource(7))
+
+	for trial := 0; trial < 100; trial++ {
+		patterns, _, _ := scramble(rng, decoder.SevenSegmentDigits)
+		sd, err := decoder.NewSymbolDecoder(decoder.SevenSegmentDigits, patterns)
+
+		if err != nil {
+			t.Fatalf("encountered error (%s)", err.Error())
+		}
+
+		dd, err := decoder.NewDigitDecoder(patterns)
+
+		if err != nil {
+			t.Fatalf("encountered error (%s)", err.Error())
+		}
+
+		for _, pattern := range patterns {
+			symbol, _ := sd.Decode(pattern)
+			digit, _ := dd.Decode(pattern)
+
+			if symbol != string(rune('0'+digit)) {
+				t.Errorf("pattern %s: digit decoder %d, symbol decoder %s", pattern, digit, symbol)
+			}
+		}
+	}
+}
+
+func TestSymbolDecoderPartialSymbols(t *testing.T) {
+	display := decoder.FourteenSegmentAlphanumeric
+
+	// Symbols of HELLO WORLD do not determine the wiring, but only W could also be N.
+	patterns := []string{"bcefgh", "adefg", "def", "abcdef", "bcefln", "abefghn", "abcdjm"}
+	names := []string{"H", "E", "L", "O", "", "R", "D"}
+	sd, err := decoder.NewSymbolDecoder(display, patterns)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	if sd.Wiring() != nil {
+		t.Errorf("expected wiring that is not unique, actual %v", sd.Wiring())
+	}
+
+	for i, pattern := range patterns {
+		symbol, err := sd.Decode(pattern)
+
+		if names[i] == "" && (err == nil || !strings.Contains(err.Error(), "ambiguous")) {
+			t.Errorf("pattern %s: expected ambiguous pattern, actual %s (%v)", pattern, symbol, err)
+		} else if names[i] != "" && (err != nil || symbol != names[i]) {
+			t.Errorf("pattern %s: expected %s, actual %s (%v)", pattern, names[i], symbol, err)
+		}
+	}
+
+	// Patterns that were not observed cannot be decoded without a unique wiring.
+	if _, err := sd.Decode("abc"); err == nil {
+		t.Errorf("expected error for pattern that was not observed")
+	}
+
+	rng := rand.New(rand.NewSource(14))
+	decodedAll := 0
+
+	for trial := 0; trial < 50; trial++ {
+		patterns, names, _ := scramble(rng, display)
+		patterns, names = patterns[:10], names[:10]
+		sd, err := decoder.NewSymbolDecoder(display, patterns)
+
+		if err != nil {
+			t.Fatalf("encountered error (%s)", err.Error())
+		}
+
+		ambiguous := 0
+
+		for i, pattern := range patterns {
+			symbol, err := sd.Decode(pattern)
+
+			if err != nil && strings.Contains(err.Error(), "ambiguous") {
+				ambiguous++
+			} else if err != nil || symbol != names[i] {
+				t.Errorf("pattern %s: expected %s, actual %s (%v)", pattern, names[i], symbol, err)
+			}
+		}
+
+		if ambiguous == 0 && sd.Wiring() == nil {
+			decodedAll++
+		}
+	}
+
+	if decodedAll == 0 {
+		t.Errorf("expected some partial symbol sets to decode without a unique wiring")
+	}
+}
+
+func TestSymbolDecoderErrors(t *testing.T) {
+	testCases := map[string][]string{
+		"inconsistent": {"ab", "cd"},
+		"no symbol":    {"abcde", "ab", "abc", "abdf", "abcdefg", "abcdf", "bcdef", "acdefg", "abcdeg", "abef"},
+		"unknown wire": {"xy"},
+	}
+
+	for name, patterns := range testCases {
+		if _, err := decoder.NewSymbolDecoder(decoder.SevenSegmentDigits, patterns); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestParseDisplay(t *testing.T) {
+	definition := `# Seven segment hexadecimal digits.
+0 abcefg
+1 cf
+2 acdeg
+3 acdfg
+4 bcdf
+5 abdfg
+6 abdefg
+7 acf
+8 abcdefg
+9 abcdfg
+A abcdef
+b bdefg
+C abeg
+d cdefg
+E abdeg
+F abde
+`
+	display, err := decoder.ParseDisplay(strings.NewReader(definition))
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	if display.Segments != "abcdefg" || len(display.Symbols) != 16 {
+		t.Fatalf("expected 16 symbols on segments abcdefg, actual %d on %s", len(display.Symbols), display.Segments)
+	}
+
+	patterns, names, _ := scramble(rand.New(rand.NewSource(16)), display)
+	sd, err := decoder.NewSymbolDecoder(display, patterns)
+
+	if err != nil {
+		t.Fatalf("encountered error (%s)", err.Error())
+	}
+
+	for i, pattern := range patterns {
+		if symbol, _ := sd.Decode(pattern); symbol != names[i] {
+			t.Errorf("pattern %s: expected %s, actual %s", pattern, names[i], symbol)
+		}
+	}
+
+	invalid := map[string]string{
+		"duplicate name":     "1 cf\n1 ab\n",
+		"duplicate segments": "1 cf\n7 fc\n",
+		"bad line":           "1 c f\n",
+		"empty":              "# nothing\n",
+	}
+
+	for name, definition := range invalid {
+		if _, err := decoder.ParseDisplay(strings.NewReader(definition)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestUniqueSizeSymbols(t *testing.T) {
+	names := decoder.SevenSegmentDigits.UniqueSizeSymbols()
+
+	if strings.Join(names, "") != "1478" {
+		t.Errorf("expected symbols 1, 4, 7 and 8, actual %v", names)
+	}
+}
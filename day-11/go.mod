@@ -2,4 +2,4 @@ module github.com/PrimozLavric/advent-of-code-2021/day-11
 
 go 1.17
 
-require github.com/deckarep/golang-set v1.7.1 // indirect
+require github.com/deckarep/golang-set v1.7.1